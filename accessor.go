@@ -0,0 +1,151 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package reflection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// pathStep 预先解析的路径片段
+type pathStep struct {
+	//结构体字段索引路径
+	index []int
+	//slice、array下标
+	pos int
+	//map key
+	key reflect.Value
+}
+
+// Accessor 预先按类型解析的路径，可以并发使用
+type Accessor struct {
+	root reflect.Type
+	typ  reflect.Type
+	w    *pathWalker
+}
+
+// CompilePath 按类型t预先解析路径，路径语法见SetFieldValueEx，opts与Set、Get一致。
+// 字段索引、下标以及map key只解析一次，路径经过interface时其后的片段在运行时解析。
+func CompilePath(t reflect.Type, path string, opts ...PathOption) (*Accessor, error) {
+	if t == nil {
+		return nil, errors.New("CompilePath type must not be nil. ")
+	}
+	w, err := newPathWalker(path, newPathOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	root := indirectType(t)
+	typ, err := w.compile(root)
+	if err != nil {
+		return nil, err
+	}
+	return &Accessor{
+		root: root,
+		typ:  typ,
+		w:    w,
+	}, nil
+}
+
+// Type 返回路径指向的值的类型，路径经过interface时返回该interface类型
+func (a *Accessor) Type() reflect.Type {
+	return a.typ
+}
+
+// Get 获得obj中路径指向的值，obj可以是编译类型的值或指针
+func (a *Accessor) Get(obj interface{}) (interface{}, error) {
+	v := reflect.ValueOf(obj)
+	if err := a.checkType(v); err != nil {
+		return nil, err
+	}
+	fv, err := a.w.get(v)
+	if err != nil {
+		return nil, err
+	}
+	if !fv.CanInterface() {
+		return nil, fmt.Errorf("Path %s cannot be accessed. ", a.w.path)
+	}
+	return fv.Interface(), nil
+}
+
+// Set 设置obj中路径指向的值，obj必须是编译类型的非nil指针
+func (a *Accessor) Set(obj interface{}, value interface{}) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("Set dest object must be non-nil pointer. ")
+	}
+	if err := a.checkType(v); err != nil {
+		return err
+	}
+	return a.w.set(v, reflect.ValueOf(value))
+}
+
+func (a *Accessor) checkType(v reflect.Value) error {
+	if !v.IsValid() || indirectType(v.Type()) != a.root {
+		return fmt.Errorf("Accessor of %s cannot access %v. ", a.root, v.Type())
+	}
+	return nil
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// compile 按类型解析所有片段，返回路径指向的值的类型
+func (w *pathWalker) compile(t reflect.Type) (reflect.Type, error) {
+	steps := make([]*pathStep, len(w.segments))
+	for i, seg := range w.segments {
+		t = indirectType(t)
+		if t.Kind() == reflect.Interface {
+			break
+		}
+		step := &pathStep{}
+		switch seg.kind {
+		case segmentField:
+			if t.Kind() != reflect.Struct {
+				return nil, w.pathError(i, ErrFieldNotFound)
+			}
+			index, err := w.fieldIndex(i, seg, t)
+			if err != nil {
+				return nil, err
+			}
+			step.index = index
+			t = t.FieldByIndex(index).Type
+		case segmentIndex:
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array:
+				pos, err := w.position(i, seg)
+				if err != nil {
+					return nil, err
+				}
+				if pos < 0 || (t.Kind() == reflect.Array && pos >= t.Len()) {
+					return nil, w.pathError(i, ErrIndexOutOfRange)
+				}
+				step.pos = pos
+			case reflect.Map:
+				key, err := w.convertKey(i, seg, t.Key())
+				if err != nil {
+					return nil, err
+				}
+				step.key = key
+			default:
+				return nil, w.pathError(i, ErrInvalidPath)
+			}
+			t = t.Elem()
+		case segmentAppend:
+			if t.Kind() != reflect.Slice {
+				return nil, w.pathError(i, ErrInvalidPath)
+			}
+			t = t.Elem()
+		}
+		steps[i] = step
+	}
+	w.steps = steps
+	return t, nil
+}
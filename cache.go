@@ -0,0 +1,362 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package reflection
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// fieldDescriptor 解析后的字段信息
+type fieldDescriptor struct {
+	//字段名称
+	Name string
+	//映射名称
+	Alias string
+	//字段索引路径，用于FieldByIndex
+	Index []int
+	//字段类型
+	Type reflect.Type
+	//tag选项
+	Options TagOptions
+
+	//是否通过tag指定了映射名称
+	tagged bool
+}
+
+// structDescriptor 解析后的结构体信息，按类型缓存，只读
+type structDescriptor struct {
+	Type      reflect.Type
+	Name      string
+	ClassName string
+	Fields    []*fieldDescriptor
+	//映射名称与字段名称的映射关系，GetReflectStructInfo返回其副本
+	FieldNameMap map[string]string
+
+	fieldsByAlias map[string]*fieldDescriptor
+	//命名策略，不为nil时映射名称按命名策略归一化后再次匹配
+	naming       NamingStrategy
+	fieldsByName map[string]*fieldDescriptor
+
+	//StructInfo.Fields返回的字段信息，第一次调用时生成
+	infosOnce sync.Once
+	infos     []FieldInfo
+}
+
+type structCacheKey struct {
+	t reflect.Type
+	//以逗号连接的tag名称列表
+	tags       string
+	naming     uintptr
+	unexported bool
+}
+
+var structCache sync.Map
+
+// InvalidateStructCache 删除类型t的结构体解析缓存
+func InvalidateStructCache(t reflect.Type) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	structCache.Range(func(key, value interface{}) bool {
+		if key.(structCacheKey).t == t {
+			structCache.Delete(key)
+		}
+		return true
+	})
+}
+
+// ResetStructCache 清空所有结构体解析缓存
+func ResetStructCache() {
+	structCache.Range(func(key, value interface{}) bool {
+		structCache.Delete(key)
+		return true
+	})
+}
+
+// getStructDescriptor 获得结构体解析信息，每个类型（及tag列表）只解析一次，rt必须是struct类型。
+// tags为按优先级排列的tag名称，映射名称的解析规则见resolveTag，为空时使用字段名称；
+// naming不为nil时没有tag名称的字段使用naming转换后的字段名称，自定义命名策略不缓存；unexported为true时包含未导出字段
+func getStructDescriptor(rt reflect.Type, tags []string, naming NamingStrategy, unexported bool) *structDescriptor {
+	nk, cacheable := namingKey(naming)
+	if !cacheable {
+		return parseStructDescriptor(rt, tags, naming, unexported)
+	}
+	key := structCacheKey{t: rt, tags: strings.Join(tags, ","), naming: nk, unexported: unexported}
+	if v, ok := structCache.Load(key); ok {
+		return v.(*structDescriptor)
+	}
+	v, _ := structCache.LoadOrStore(key, parseStructDescriptor(rt, tags, naming, unexported))
+	return v.(*structDescriptor)
+}
+
+func parseStructDescriptor(rt reflect.Type, tags []string, naming NamingStrategy, unexported bool) *structDescriptor {
+	ret := &structDescriptor{
+		Type: rt,
+		//Default name is struct name
+		Name:          rt.Name(),
+		ClassName:     GetTypeClassName(rt),
+		FieldNameMap:  map[string]string{},
+		fieldsByAlias: map[string]*fieldDescriptor{},
+		naming:        naming,
+	}
+
+	for _, f := range promoteFields(rt, tags, naming, unexported) {
+		ret.addField(f)
+	}
+	if naming != nil {
+		ret.fieldsByName = map[string]*fieldDescriptor{}
+		for _, f := range ret.Fields {
+			key := naming(f.Alias)
+			if _, ok := ret.fieldsByName[key]; ok {
+				// 归一化后重名，存在歧义，只能精确匹配
+				ret.fieldsByName[key] = nil
+			} else {
+				ret.fieldsByName[key] = f
+			}
+		}
+	}
+	return ret
+}
+
+// promoteFields 解析结构体字段，按照Go的提升规则展开匿名结构体（及匿名结构体指针）字段，
+// 同名字段按照encoding/json的规则处理：
+// 1、层级最浅的字段优先；
+// 2、同一层级中有且只有一个字段含有tag名称时该字段优先；
+// 3、否则存在歧义，所有同名字段均忽略。
+// 展开规则见inlineField，unexported为false时未导出的字段忽略。
+func promoteFields(rt reflect.Type, tags []string, naming NamingStrategy, unexported bool) []*fieldDescriptor {
+	type embedded struct {
+		t     reflect.Type
+		index []int
+	}
+
+	var fields []*fieldDescriptor
+	current := []embedded{}
+	next := []embedded{{t: rt}}
+	visited := map[reflect.Type]bool{}
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		// 同一层级中重复的类型都需要解析，使其字段产生歧义
+		for _, e := range current {
+			if visited[e.t] {
+				continue
+			}
+
+			//字段解析
+			for i, j := 0, e.t.NumField(); i < j; i++ {
+				rtf := e.t.Field(i)
+
+				//if rtf.Type == modelNameType {
+				//    if rtf.Tag != "" {
+				//        objInfo.Name = string(rtf.Tag)
+				//    } else {
+				//        objInfo.Name = rtf.Name
+				//    }
+				//    continue
+				//}
+
+				if rtf.Tag == "-" {
+					continue
+				}
+				ft := rtf.Type
+				if rtf.Anonymous {
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					if rtf.PkgPath != "" && ft.Kind() != reflect.Struct && !unexported {
+						continue
+					}
+				} else if rtf.PkgPath != "" && !unexported {
+					continue
+				}
+
+				//没有tag,表字段名与实体字段名一致
+				name, opts, skip := resolveTag(rtf.Tag, tags)
+				if skip {
+					continue
+				}
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+
+				if et, ok := inlineField(rtf, name, opts); ok {
+					next = append(next, embedded{t: et, index: index})
+					continue
+				}
+				// 未导出的匿名结构体不展开时无法访问
+				if rtf.PkgPath != "" && !unexported {
+					continue
+				}
+
+				f := &fieldDescriptor{
+					Name:    rtf.Name,
+					Alias:   name,
+					Index:   index,
+					Type:    rtf.Type,
+					Options: opts,
+					tagged:  name != "",
+				}
+				if f.Alias == "" {
+					f.Alias = rtf.Name
+					if naming != nil {
+						f.Alias = naming(rtf.Name)
+					}
+				}
+				fields = append(fields, f)
+			}
+		}
+		for _, e := range current {
+			visited[e.t] = true
+		}
+	}
+
+	return dominantFields(fields)
+}
+
+// inlineField 字段是否展开到父结构体中，返回展开的结构体类型：
+// 没有tag名称且不含noinline选项的匿名字段，以及含有squash、inline选项的字段展开。
+// 字段类型须为结构体或结构体指针，time.Time等叶子类型（见isEncodeLeaf）不展开
+func inlineField(sf reflect.StructField, name string, opts TagOptions) (reflect.Type, bool) {
+	if !(sf.Anonymous && name == "" && !opts.Contains(tagOptionNoInline)) &&
+		!opts.Contains(tagOptionSquash) && !opts.Contains(tagOptionInline) {
+		return nil, false
+	}
+	ft := sf.Type
+	if ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	return ft, ft.Kind() == reflect.Struct && !isEncodeLeaf(ft)
+}
+
+// dominantFields 处理同名字段，fields按层级排列
+func dominantFields(fields []*fieldDescriptor) []*fieldDescriptor {
+	byAlias := map[string][]*fieldDescriptor{}
+	for _, f := range fields {
+		byAlias[f.Alias] = append(byAlias[f.Alias], f)
+	}
+	ret := make([]*fieldDescriptor, 0, len(fields))
+	for _, f := range fields {
+		same := byAlias[f.Alias]
+		if len(same) == 1 {
+			ret = append(ret, f)
+			continue
+		}
+		// 选出最浅层级中的字段
+		depth := len(same[0].Index)
+		var dominant *fieldDescriptor
+		ambiguous := false
+		for _, x := range same {
+			if len(x.Index) > depth {
+				break
+			}
+			if dominant == nil || (x.tagged && !dominant.tagged) {
+				dominant = x
+				ambiguous = false
+			} else if x.tagged == dominant.tagged {
+				ambiguous = true
+			}
+		}
+		if !ambiguous && dominant == f {
+			ret = append(ret, f)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return lessIndex(ret[i].Index, ret[j].Index)
+	})
+	return ret
+}
+
+func lessIndex(a, b []int) bool {
+	for i := range a {
+		if i >= len(b) {
+			return false
+		}
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+func (desc *structDescriptor) addField(f *fieldDescriptor) {
+	desc.Fields = append(desc.Fields, f)
+	desc.FieldNameMap[f.Alias] = f.Name
+	desc.fieldsByAlias[f.Alias] = f
+}
+
+func isUnmarshaler(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	return pt.Implements(textUnmarshalerType) || pt.Implements(sqlScannerType) || pt.Implements(jsonUnmarshalerType)
+}
+
+// fieldInfos 获得导出的字段信息，字段类型对应的Object在第一次使用时解析
+func (desc *structDescriptor) fieldInfos() []FieldInfo {
+	desc.infosOnce.Do(func() {
+		desc.infos = make([]FieldInfo, len(desc.Fields))
+		for i, f := range desc.Fields {
+			desc.infos[i] = FieldInfo{
+				Name:    f.Name,
+				Alias:   f.Alias,
+				Options: f.Options,
+				Index:   f.Index,
+				Type:    f.Type,
+				obj:     &fieldObject{},
+			}
+		}
+	})
+	return desc.infos
+}
+
+// field 根据映射名称获得字段信息，优先精确匹配，其次按命名策略归一化后匹配
+func (desc *structDescriptor) field(alias string) *fieldDescriptor {
+	if f, ok := desc.fieldsByAlias[alias]; ok {
+		return f
+	}
+	if desc.naming != nil {
+		return desc.fieldsByName[desc.naming(alias)]
+	}
+	return nil
+}
+
+// fieldByIndexForSet 按索引路径获得字段，路径上的nil嵌入指针会分配新值，无法分配时返回无效值
+func fieldByIndexForSet(v reflect.Value, index []int) reflect.Value {
+	if len(index) == 1 {
+		return v.Field(index[0])
+	}
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// fieldByIndexForGet 按索引路径获得字段，路径上存在nil嵌入指针时返回无效值
+func fieldByIndexForGet(v reflect.Value, index []int) reflect.Value {
+	if len(index) == 1 {
+		return v.Field(index[0])
+	}
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
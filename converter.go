@@ -0,0 +1,131 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package reflection
+
+import (
+	"reflect"
+	"sync"
+)
+
+// ConverterFunc 自定义转换函数。
+// 返回true表示已完成赋值；返回false且error为nil表示不处理，继续使用内置转换规则
+type ConverterFunc func(dst, src reflect.Value) (bool, error)
+
+type converterKey struct {
+	src reflect.Type
+	dst reflect.Type
+}
+
+// ConverterRegistry 转换器注册表，SetValue在内置转换规则之前查询
+type ConverterRegistry struct {
+	lock           sync.RWMutex
+	typeConverters map[converterKey]ConverterFunc
+	kindConverters map[reflect.Kind]ConverterFunc
+}
+
+// DefaultConverterRegistry 默认转换器注册表，SetValue等未指定注册表的函数使用
+var DefaultConverterRegistry = NewConverterRegistry()
+
+func NewConverterRegistry() *ConverterRegistry {
+	return &ConverterRegistry{
+		typeConverters: map[converterKey]ConverterFunc{},
+		kindConverters: map[reflect.Kind]ConverterFunc{},
+	}
+}
+
+// RegisterConverter 向默认注册表注册(源类型, 目标类型)转换器
+func RegisterConverter(srcType, dstType reflect.Type, converter ConverterFunc) {
+	DefaultConverterRegistry.Register(srcType, dstType, converter)
+}
+
+// RegisterKindConverter 向默认注册表注册目标Kind转换器
+func RegisterKindConverter(dstKind reflect.Kind, converter ConverterFunc) {
+	DefaultConverterRegistry.RegisterKind(dstKind, converter)
+}
+
+// Register 注册(源类型, 目标类型)转换器，converter为nil时删除
+func (r *ConverterRegistry) Register(srcType, dstType reflect.Type, converter ConverterFunc) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	key := converterKey{src: srcType, dst: dstType}
+	if converter == nil {
+		delete(r.typeConverters, key)
+	} else {
+		r.typeConverters[key] = converter
+	}
+}
+
+// RegisterKind 注册目标Kind转换器，优先级低于(源类型, 目标类型)转换器，converter为nil时删除
+func (r *ConverterRegistry) RegisterKind(dstKind reflect.Kind, converter ConverterFunc) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if converter == nil {
+		delete(r.kindConverters, dstKind)
+	} else {
+		r.kindConverters[dstKind] = converter
+	}
+}
+
+// Clone 复制注册表，可在默认注册表的基础上生成调用级别的注册表
+func (r *ConverterRegistry) Clone() *ConverterRegistry {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	ret := NewConverterRegistry()
+	for k, v := range r.typeConverters {
+		ret.typeConverters[k] = v
+	}
+	for k, v := range r.kindConverters {
+		ret.kindConverters[k] = v
+	}
+	return ret
+}
+
+// Lookup 查找转换器，先匹配(源类型, 目标类型)，再匹配目标Kind
+func (r *ConverterRegistry) Lookup(srcType, dstType reflect.Type) ConverterFunc {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if len(r.typeConverters) > 0 {
+		if f, ok := r.typeConverters[converterKey{src: srcType, dst: dstType}]; ok {
+			return f
+		}
+	}
+	if len(r.kindConverters) > 0 {
+		if f, ok := r.kindConverters[dstType.Kind()]; ok {
+			return f
+		}
+	}
+	return nil
+}
+
+// Convert 使用注册的转换器赋值，未找到转换器时返回false
+func (r *ConverterRegistry) Convert(dst, src reflect.Value) (bool, error) {
+	f := r.Lookup(src.Type(), dst.Type())
+	if f == nil {
+		return false, nil
+	}
+	return f(dst, src)
+}
+
+// SetValue 使用该注册表赋值，注册的转换器未处理时使用内置转换规则
+func (r *ConverterRegistry) SetValue(dst reflect.Value, value reflect.Value) bool {
+	return setValue(dst, value, r)
+}
+
+// SetValueE 使用该注册表赋值，失败时返回*ConversionError
+func (r *ConverterRegistry) SetValueE(dst reflect.Value, value reflect.Value) error {
+	return setValueE(dst, value, r)
+}
+
+func selectRegistry(registry []*ConverterRegistry) *ConverterRegistry {
+	if len(registry) > 0 && registry[0] != nil {
+		return registry[0]
+	}
+	return DefaultConverterRegistry
+}
@@ -0,0 +1,198 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package reflection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// DeepCopyOption 配置深拷贝
+type DeepCopyOption func(c *copier)
+
+type copier struct {
+	unexported bool
+	// 已拷贝的指针、map、slice，用于保持共享引用以及处理循环引用
+	visited map[copyRef]reflect.Value
+}
+
+type copyRef struct {
+	p   uintptr
+	t   reflect.Type
+	len int
+	cap int
+}
+
+// CopyUnexported 通过unsafe深拷贝未导出字段，默认未导出字段与源值共享（浅拷贝）
+func CopyUnexported() DeepCopyOption {
+	return func(c *copier) {
+		c.unexported = true
+	}
+}
+
+// DeepCopy 深拷贝src，返回与src类型相同的值：
+// 1、递归拷贝结构体、指针、slice、array、map以及interface中的值，chan、func浅拷贝；
+// 2、指向同一地址的指针（及同一map、slice）拷贝后仍然共享，循环引用保持循环；
+// 3、类型T实现了DeepCopy() T方法时调用该方法拷贝；
+// 4、time.Time直接赋值，未导出字段默认浅拷贝，使用CopyUnexported选项深拷贝。
+func DeepCopy(src interface{}, opts ...DeepCopyOption) interface{} {
+	if src == nil {
+		return nil
+	}
+	v := reflect.ValueOf(src)
+	dst := reflect.New(v.Type()).Elem()
+	newCopier(opts).copy(dst, v)
+	return dst.Interface()
+}
+
+// DeepCopyValue 将src深拷贝到dst，dst必须可设置且与src类型相同，规则同DeepCopy
+func DeepCopyValue(dst, src reflect.Value, opts ...DeepCopyOption) error {
+	if !dst.IsValid() || !dst.CanSet() {
+		return errors.New("DeepCopy dest value must be settable. ")
+	}
+	if !src.IsValid() {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if dst.Type() != src.Type() {
+		return fmt.Errorf("DeepCopy type mismatch: dest %s src %s. ", dst.Type(), src.Type())
+	}
+	if !src.CanInterface() {
+		return errors.New("DeepCopy src value cannot be accessed. ")
+	}
+	newCopier(opts).copy(dst, src)
+	return nil
+}
+
+func newCopier(opts []DeepCopyOption) *copier {
+	c := &copier{
+		visited: map[copyRef]reflect.Value{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// copy 将src拷贝到dst，dst可设置且与src类型相同
+func (c *copier) copy(dst, src reflect.Value) {
+	if m, ok := deepCopyMethod(src); ok {
+		ret := m.Call(nil)[0]
+		if src.Kind() == reflect.Ptr {
+			c.visited[copyRef{p: src.Pointer(), t: src.Type()}] = ret
+		}
+		dst.Set(ret)
+		return
+	}
+
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(src.Type()))
+			return
+		}
+		ref := copyRef{p: src.Pointer(), t: src.Type()}
+		if v, ok := c.visited[ref]; ok {
+			dst.Set(v)
+			return
+		}
+		n := reflect.New(src.Type().Elem())
+		c.visited[ref] = n
+		c.copy(n.Elem(), src.Elem())
+		dst.Set(n)
+	case reflect.Interface:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(src.Type()))
+			return
+		}
+		e := src.Elem()
+		n := reflect.New(e.Type()).Elem()
+		c.copy(n, e)
+		dst.Set(n)
+	case reflect.Struct:
+		// 整体赋值，未导出字段默认与源值共享
+		dst.Set(src)
+		if src.Type().ConvertibleTo(TimeType) {
+			return
+		}
+		if c.unexported {
+			src = addressable(src)
+		}
+		t := src.Type()
+		for i := 0; i < t.NumField(); i++ {
+			df, sf := dst.Field(i), src.Field(i)
+			if t.Field(i).PkgPath != "" {
+				if !c.unexported {
+					continue
+				}
+				df, sf = exposeField(df), exposeField(sf)
+			}
+			c.copy(df, sf)
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(src.Type()))
+			return
+		}
+		ref := copyRef{p: src.Pointer(), t: src.Type(), len: src.Len(), cap: src.Cap()}
+		if v, ok := c.visited[ref]; ok {
+			dst.Set(v)
+			return
+		}
+		n := reflect.MakeSlice(src.Type(), src.Len(), src.Cap())
+		c.visited[ref] = n
+		for i := 0; i < src.Len(); i++ {
+			c.copy(n.Index(i), src.Index(i))
+		}
+		dst.Set(n)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			c.copy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(src.Type()))
+			return
+		}
+		ref := copyRef{p: src.Pointer(), t: src.Type()}
+		if v, ok := c.visited[ref]; ok {
+			dst.Set(v)
+			return
+		}
+		n := reflect.MakeMapWithSize(src.Type(), src.Len())
+		c.visited[ref] = n
+		kt, et := src.Type().Key(), src.Type().Elem()
+		iter := src.MapRange()
+		for iter.Next() {
+			k := reflect.New(kt).Elem()
+			c.copy(k, iter.Key())
+			e := reflect.New(et).Elem()
+			c.copy(e, iter.Value())
+			n.SetMapIndex(k, e)
+		}
+		dst.Set(n)
+	default:
+		dst.Set(src)
+	}
+}
+
+// deepCopyMethod 获得值的DeepCopy() T方法，nil指针、interface不调用
+func deepCopyMethod(v reflect.Value) (reflect.Value, bool) {
+	switch v.Kind() {
+	case reflect.Interface:
+		return reflect.Value{}, false
+	case reflect.Ptr:
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+	}
+	m, ok := v.Type().MethodByName("DeepCopy")
+	if !ok || m.Type.NumIn() != 1 || m.Type.NumOut() != 1 || m.Type.Out(0) != v.Type() {
+		return reflect.Value{}, false
+	}
+	return v.Method(m.Index), true
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package reflection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedConversion 源类型与目标类型之间不支持转换
+	ErrUnsupportedConversion = errors.New("Unsupported conversion. ")
+	// ErrInvalidValue 目标值无效或不可设置
+	ErrInvalidValue = errors.New("Invalid value. ")
+
+	// ErrFieldNotFound 路径中的字段不存在
+	ErrFieldNotFound = errors.New("not found. ")
+	// ErrNilOnPath 路径中的指针为nil
+	ErrNilOnPath = errors.New("is nil. ")
+	// ErrIndexOutOfRange 路径中的slice、array下标越界
+	ErrIndexOutOfRange = errors.New("index out of range. ")
+	// ErrKeyNotFound 路径中的map key不存在
+	ErrKeyNotFound = errors.New("key not found. ")
+	// ErrInvalidPath 路径语法错误，或路径片段与值的类型不匹配
+	ErrInvalidPath = errors.New("invalid path. ")
+)
+
+// ConversionError 赋值转换错误，Cause为具体原因，可通过errors.Is/errors.As判断
+type ConversionError struct {
+	SrcType reflect.Type
+	DstType reflect.Type
+	Value   interface{}
+	Cause   error
+}
+
+func (e *ConversionError) Error() string {
+	return fmt.Sprintf("Cannot convert %v(%v) to %v: %v", e.SrcType, e.Value, e.DstType, e.Cause)
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Cause
+}
+
+func newConversionError(dst, src reflect.Value, cause error) *ConversionError {
+	ret := &ConversionError{
+		Cause: cause,
+	}
+	if dst.IsValid() {
+		ret.DstType = dst.Type()
+	}
+	if src.IsValid() {
+		ret.SrcType = src.Type()
+		if src.CanInterface() {
+			ret.Value = src.Interface()
+		}
+	}
+	return ret
+}
+
+// PathError 字段路径错误，Err为具体原因（ErrFieldNotFound、ErrNilOnPath、ErrIndexOutOfRange、ErrKeyNotFound等）
+type PathError struct {
+	//完整路径
+	Path string
+	//出错的字段
+	Field string
+	//出错的字段在路径中的位置
+	Index int
+	Err   error
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("Field: %s at %d %v", e.Field, e.Index, e.Err)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// ElementError slice、array、map中单个元素的转换错误
+type ElementError struct {
+	//slice下标，map元素时为-1
+	Index int
+	//map key，slice元素时为nil
+	Key interface{}
+	Err error
+}
+
+func (e *ElementError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("[%v] %v", e.Key, e.Err)
+	}
+	return fmt.Sprintf("[%d] %v", e.Index, e.Err)
+}
+
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+// CopyError CopySlice、CopyMap中转换失败的元素，按下标（map按key的字符串形式）排列
+type CopyError struct {
+	Elements []*ElementError
+}
+
+func (e *CopyError) Error() string {
+	buf := strings.Builder{}
+	buf.WriteString("Copy failed: ")
+	for i, ee := range e.Elements {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(ee.Error())
+	}
+	return buf.String()
+}
+
+// Unwrap 返回第一个元素的错误
+func (e *CopyError) Unwrap() error {
+	if len(e.Elements) == 0 {
+		return nil
+	}
+	return e.Elements[0]
+}
@@ -9,7 +9,6 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"strings"
 )
 
 func SetStrcutFieldValue(o interface{}, fieldStr string, value interface{}) error {
@@ -32,64 +31,120 @@ func SetFieldValueByTag(dst reflect.Value, tags string, value reflect.Value, tag
 	return SetFieldValueEx(dst, tags, value, tagName, nil)
 }
 
-func SetFieldValueEx(v reflect.Value, fieldName string, value reflect.Value, tagName string, modifier func(string) string) error {
+// SetFieldValueEx 按照路径设置结构体字段值，v必须是结构体指针。路径语法：
+// 1、以‘.’分隔结构体字段，如Order.Customer.Name；
+// 2、[n]为slice、array下标，如Items[3].Price、Matrix[1][2]；
+// 3、["key"]为map key，不带引号时（如Labels[env]、Scores[42]）按SetValue规则转换为map key类型；
+// 4、[]或[-]表示在slice末尾添加元素，如Items[].Price。
+// tagName不为空时字段匹配tag名称（忽略tag选项），没有该tag名称的字段匹配字段名称，tag名称为‘-’的字段忽略；
+// modifier不为nil时匹配前先修改路径中的字段名称。
+func SetFieldValueEx(v reflect.Value, fieldName string, value reflect.Value, tagName string, modifier func(string) string, registry ...*ConverterRegistry) error {
 	t := v.Type()
 	if t.Kind() != reflect.Ptr {
 		return errors.New("Set dest object must be struct pointer. ")
 	}
-	t = t.Elem()
-	v = v.Elem()
-	if t.Kind() != reflect.Struct {
+	if t.Elem().Kind() != reflect.Struct {
 		return errors.New("Set dest object must be struct pointer. ")
 	}
 
-	fields := strings.Split(fieldName, ".")
-	fv := v
-	ft := t
-	for i, f := range fields {
-		f = strings.TrimSpace(f)
-		if f == "" {
-			continue
-		}
-		if modifier != nil {
-			f = modifier(f)
-		}
-		if tagName != "" {
-			if ft.Kind() == reflect.Ptr {
-				ft = ft.Elem()
-				fv = fv.Elem()
-			}
-			fs := fv.NumField()
-			found := false
-			for j := 0; j < fs; j++ {
-				ff := ft.Field(j)
-				if tag, ok := ff.Tag.Lookup(tagName); ok {
-					if tag == f {
-						ft = ff.Type
-						fv = fv.Field(j)
-
-						found = true
-						break
-					}
-				}
-			}
-			if !found {
-				return fmt.Errorf("Field: %s at %d not found. ", f, i)
-			}
-		} else {
-			if fv.Kind() == reflect.Ptr {
-				fv = fv.Elem()
-			}
-			fv = fv.FieldByName(f)
-			if !fv.IsValid() {
-				return fmt.Errorf("Field: %s at %d not found. ", f, i)
-			}
+	w, err := newPathWalker(fieldName, pathOptions{tagNames: tagNames(tagName), modifier: modifier, registry: selectRegistry(registry)})
+	if err != nil {
+		return err
+	}
+	if err := w.set(v, value); err != nil {
+		var pe *PathError
+		if errors.As(err, &pe) {
+			return err
 		}
+		return fmt.Errorf("Value type is not assiginable to field: %w", err)
 	}
+	return nil
+}
+
+func GetFieldValue(v reflect.Value, fieldName string) (reflect.Value, error) {
+	return GetFieldValueByTag(v, fieldName, "")
+}
+
+func GetFieldValueByTag(v reflect.Value, fieldName string, tagName string) (reflect.Value, error) {
+	return GetFieldValueEx(v, fieldName, tagName, nil)
+}
+
+// GetFieldValueEx 获得字段值，fieldName、tagName、modifier的规则与SetFieldValueEx一致。
+// v可以是结构体或结构体指针，路径上存在nil指针时返回ErrNilOnPath
+func GetFieldValueEx(v reflect.Value, fieldName string, tagName string, modifier func(string) string) (reflect.Value, error) {
+	t := v.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return reflect.Value{}, errors.New("Get src object must be struct or struct pointer. ")
+	}
+	w, err := newPathWalker(fieldName, pathOptions{tagNames: tagNames(tagName), modifier: modifier, registry: DefaultConverterRegistry})
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return w.get(v)
+}
+
+// GetFieldString 获得字段值并按SetValue的规则转换为string
+func GetFieldString(o interface{}, fieldName string) (string, error) {
+	var ret string
+	err := getFieldAs(o, fieldName, &ret)
+	return ret, err
+}
+
+// GetFieldInt 获得字段值并按SetValue的规则转换为int64
+func GetFieldInt(o interface{}, fieldName string) (int64, error) {
+	var ret int64
+	err := getFieldAs(o, fieldName, &ret)
+	return ret, err
+}
+
+// GetFieldUint 获得字段值并按SetValue的规则转换为uint64
+func GetFieldUint(o interface{}, fieldName string) (uint64, error) {
+	var ret uint64
+	err := getFieldAs(o, fieldName, &ret)
+	return ret, err
+}
+
+// GetFieldFloat 获得字段值并按SetValue的规则转换为float64
+func GetFieldFloat(o interface{}, fieldName string) (float64, error) {
+	var ret float64
+	err := getFieldAs(o, fieldName, &ret)
+	return ret, err
+}
+
+// GetFieldBool 获得字段值并按SetValue的规则转换为bool
+func GetFieldBool(o interface{}, fieldName string) (bool, error) {
+	var ret bool
+	err := getFieldAs(o, fieldName, &ret)
+	return ret, err
+}
+
+func getFieldAs(o interface{}, fieldName string, dst interface{}) error {
+	if o == nil {
+		return errors.New("Get src object must be struct or struct pointer. ")
+	}
+	fv, err := GetFieldValue(reflect.ValueOf(o), fieldName)
+	if err != nil {
+		return err
+	}
+	return SetValueE(reflect.ValueOf(dst).Elem(), fv)
+}
+
+// lookupFieldIndex 在结构体中查找字段的索引路径（包括提升的字段），
+// name按照tagNames的顺序匹配tag名称，没有tag名称的字段匹配字段名称，规则与GetReflectStructInfoEx一致
+func lookupFieldIndex(t reflect.Type, name string, tagNames []string, naming NamingStrategy, unexported bool) ([]int, bool) {
+	f := getStructDescriptor(t, tagNames, naming, unexported).field(name)
+	if f == nil {
+		return nil, false
+	}
+	return f.Index, true
+}
 
-	if SetValue(fv, value) {
+func tagNames(tagName string) []string {
+	if tagName == "" {
 		return nil
-	} else {
-		return fmt.Errorf("Value type is not assiginable to field. ")
 	}
+	return []string{tagName}
 }
@@ -1 +1,3 @@
-module "github.com/xfali/reflection"
\ No newline at end of file
+module github.com/xfali/reflection
+
+go 1.13
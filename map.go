@@ -9,19 +9,23 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 )
 
-func CopyMapInterface(dest, src interface{}) (int, error) {
+func CopyMapInterface(dest, src interface{}, registry ...*ConverterRegistry) (int, error) {
 	t := reflect.TypeOf(dest)
 	v := reflect.ValueOf(dest)
 	if t.Kind() != reflect.Ptr {
 		return 0, errors.New("Dest Type is not a ptr. " + t.String())
 	}
 
-	return CopyMap(v.Elem(), reflect.ValueOf(src))
+	return CopyMap(v.Elem(), reflect.ValueOf(src), registry...)
 }
 
-func SetOrCopyMap(dest, src reflect.Value, set bool) (int, error) {
+// SetOrCopyMap 将src中的元素拷贝到dest中，set为true时替换dest，否则合并到dest中，key类型必须相同。
+// 元素转换规则与SetOrCopySlice一致，返回成功的元素个数，存在转换失败的元素时返回*CopyError：
+// set为true时dest不变，否则dest包含成功的元素
+func SetOrCopyMap(dest, src reflect.Value, set bool, registry ...*ConverterRegistry) (int, error) {
 	destType := dest.Type()
 	if destType.Kind() != reflect.Map {
 		return 0, errors.New("Dest Type is not a map ptr. " + destType.String())
@@ -33,42 +37,46 @@ func SetOrCopyMap(dest, src reflect.Value, set bool) (int, error) {
 		return 0, errors.New("Src Type is not a map. " + srcType.String())
 	}
 	srcKeyType := srcType.Key()
-	srcElemType := srcType.Elem()
 
 	if destKeyType != srcKeyType {
 		return 0, fmt.Errorf("Expect Map key type: %s but get %s. ", destKeyType.String(), srcKeyType.String())
 	}
 
-	if set && (destElemType.Kind() == srcElemType.Kind()) {
+	if set && srcType.AssignableTo(destType) {
 		dest.Set(src)
 		return dest.Len(), nil
 	} else {
 		n := 0
 		destTmp := dest
-		if destTmp.IsNil() {
+		if set || destTmp.IsNil() {
 			destTmp = reflect.MakeMapWithSize(destType, src.Len())
 		}
+		r := selectRegistry(registry)
+		var failed []*ElementError
 		keys := src.MapKeys()
 		for _, key := range keys {
-			ov := src.MapIndex(key)
-			ot := ov.Type()
-			if ot.AssignableTo(destElemType) {
-				destTmp.SetMapIndex(key, ov)
-				n++
+			ev, err := convertElem(destElemType, src.MapIndex(key), r)
+			if err != nil {
+				failed = append(failed, &ElementError{Index: -1, Key: key.Interface(), Err: err})
 				continue
 			}
-			if ot.ConvertibleTo(destElemType) {
-				destTmp.SetMapIndex(key, ov.Convert(destElemType))
-				n++
-				continue
+			destTmp.SetMapIndex(key, ev)
+			n++
+		}
+		if len(failed) > 0 {
+			sort.Slice(failed, func(i, j int) bool {
+				return fmt.Sprint(failed[i].Key) < fmt.Sprint(failed[j].Key)
+			})
+			if !set {
+				dest.Set(destTmp)
 			}
-
+			return n, &CopyError{Elements: failed}
 		}
 		dest.Set(destTmp)
 		return n, nil
 	}
 }
 
-func CopyMap(dest, src reflect.Value) (int, error) {
-	return SetOrCopyMap(dest, src, false)
+func CopyMap(dest, src reflect.Value, registry ...*ConverterRegistry) (int, error) {
+	return SetOrCopyMap(dest, src, false, registry...)
 }
@@ -4,3 +4,265 @@
 // Description:
 
 package reflection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// tag选项：将结构体字段展开到父结构体中
+	tagOptionSquash = "squash"
+	// tag选项：收集未匹配的key，字段类型须为map，key及value按照map的类型转换
+	tagOptionRemain = "remain"
+)
+
+// DecodeOption 配置Decode
+type DecodeOption func(d *decoder)
+
+type decoder struct {
+	tagNames        []string
+	caseInsensitive bool
+	registry        *ConverterRegistry
+}
+
+// DecodeTagName 指定匹配key使用的tag，默认为StructAliasTag
+func DecodeTagName(tagName string) DecodeOption {
+	return func(d *decoder) {
+		d.tagNames = tagNames(tagName)
+	}
+}
+
+// DecodeTagNames 指定匹配key使用的tag列表，按顺序使用第一个含有名称的tag，规则同WithTagNames
+func DecodeTagNames(tagNames ...string) DecodeOption {
+	return func(d *decoder) {
+		d.tagNames = tagNames
+	}
+}
+
+// DecodeCaseInsensitive 精确匹配失败时忽略大小写匹配key，多个key匹配时使用排序最小的key
+func DecodeCaseInsensitive() DecodeOption {
+	return func(d *decoder) {
+		d.caseInsensitive = true
+	}
+}
+
+// DecodeRegistry 指定叶子节点赋值使用的转换器注册表
+func DecodeRegistry(registry *ConverterRegistry) DecodeOption {
+	return func(d *decoder) {
+		d.registry = registry
+	}
+}
+
+// Decode 将map解析到结构体中：
+// 1、key与tag名称（默认为StructAliasTag）匹配，没有tag时与字段名称匹配，tag为‘-’时忽略该字段，字段解析规则与StructInfo一致；
+// 2、嵌套的map解析为嵌套的结构体，[]interface{}解析为对应类型的slice；
+// 3、匿名结构体字段（没有tag名称）以及含有squash选项的字段展开到父结构体中；
+// 4、含有remain选项的map字段收集未匹配的key，key及value按照map的类型转换；
+// 5、叶子节点使用SetValue的规则转换赋值。
+func Decode(input map[string]interface{}, outPtr interface{}, opts ...DecodeOption) error {
+	d := &decoder{
+		tagNames: []string{StructAliasTag},
+		registry: DefaultConverterRegistry,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	v := reflect.ValueOf(outPtr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("Decode dest object must be non-nil pointer. ")
+	}
+	return d.decode("", reflect.ValueOf(input), v.Elem())
+}
+
+func (d *decoder) decode(path string, in reflect.Value, out reflect.Value) error {
+	for in.IsValid() && in.Kind() == reflect.Interface && !in.IsNil() {
+		in = in.Elem()
+	}
+	if !in.IsValid() || (in.Kind() == reflect.Interface && in.IsNil()) {
+		out.Set(reflect.Zero(out.Type()))
+		return nil
+	}
+
+	var err error
+	switch out.Kind() {
+	case reflect.Ptr:
+		if in.Kind() == reflect.Ptr && in.IsNil() {
+			out.Set(reflect.Zero(out.Type()))
+			return nil
+		}
+		if in.Type().AssignableTo(out.Type()) {
+			out.Set(in)
+			return nil
+		}
+		nv := reflect.New(out.Type().Elem())
+		if err = d.decode(path, in, nv.Elem()); err == nil {
+			out.Set(nv)
+		}
+		return err
+	case reflect.Struct:
+		if in.Kind() == reflect.Map {
+			return d.decodeStruct(path, in, out)
+		}
+	case reflect.Slice:
+		if in.Kind() == reflect.Slice || in.Kind() == reflect.Array {
+			if in.Type().AssignableTo(out.Type()) || out.Type().Elem().Kind() == reflect.Uint8 {
+				break
+			}
+			nv := reflect.MakeSlice(out.Type(), in.Len(), in.Len())
+			if err = d.decodeElems(path, in, nv); err == nil {
+				out.Set(nv)
+			}
+			return err
+		}
+	case reflect.Array:
+		if in.Kind() == reflect.Slice || in.Kind() == reflect.Array {
+			if in.Len() > out.Len() {
+				return fmt.Errorf("Decode %s failed: expect at most %d elements but get %d. ", path, out.Len(), in.Len())
+			}
+			nv := reflect.New(out.Type()).Elem()
+			if err = d.decodeElems(path, in, nv); err == nil {
+				out.Set(nv)
+			}
+			return err
+		}
+	case reflect.Map:
+		if in.Kind() == reflect.Map {
+			return d.decodeMap(path, in, out)
+		}
+	}
+
+	if err = setValueE(out, in, d.registry); err != nil {
+		return fmt.Errorf("Decode %s failed: %w", path, err)
+	}
+	return nil
+}
+
+func (d *decoder) decodeElems(path string, in reflect.Value, out reflect.Value) error {
+	for i := 0; i < in.Len(); i++ {
+		if err := d.decode(path+"["+strconv.Itoa(i)+"]", in.Index(i), out.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *decoder) decodeMap(path string, in reflect.Value, out reflect.Value) error {
+	mt := out.Type()
+	nm := reflect.MakeMapWithSize(mt, in.Len())
+	iter := in.MapRange()
+	for iter.Next() {
+		k := reflect.New(mt.Key()).Elem()
+		if err := setValueE(k, iter.Key(), d.registry); err != nil {
+			return fmt.Errorf("Decode %s key failed: %w", path, err)
+		}
+		v := reflect.New(mt.Elem()).Elem()
+		if err := d.decode(fmt.Sprintf("%s[%v]", path, iter.Key()), iter.Value(), v); err != nil {
+			return err
+		}
+		nm.SetMapIndex(k, v)
+	}
+	out.Set(nm)
+	return nil
+}
+
+func (d *decoder) decodeStruct(path string, in reflect.Value, out reflect.Value) error {
+	// 取出所有string类型的key
+	inputs := make(map[string]reflect.Value, in.Len())
+	iter := in.MapRange()
+	for iter.Next() {
+		k := iter.Key()
+		for k.Kind() == reflect.Interface && !k.IsNil() {
+			k = k.Elem()
+		}
+		if k.Kind() != reflect.String {
+			return fmt.Errorf("Decode %s failed: map key must be string but get %s. ", path, k.Type())
+		}
+		inputs[k.String()] = iter.Value()
+	}
+
+	desc := getStructDescriptor(out.Type(), d.tagNames, nil, false)
+	used := make(map[string]bool, len(inputs))
+	var remain *fieldDescriptor
+	for _, f := range desc.Fields {
+		if f.Options.Contains(tagOptionRemain) {
+			if remain == nil && f.Type.Kind() == reflect.Map {
+				remain = f
+			}
+			continue
+		}
+		key, ok := d.matchKey(inputs, f.Alias)
+		if !ok {
+			continue
+		}
+		// 路径上的nil嵌入指针在赋值时分配
+		fv := fieldByIndexForSet(out, f.Index)
+		if !fv.IsValid() || !fv.CanSet() {
+			continue
+		}
+		used[key] = true
+		if err := d.decode(joinPath(path, f.Alias), inputs[key], fv); err != nil {
+			return err
+		}
+	}
+
+	if remain != nil && len(used) < len(inputs) {
+		fv := fieldByIndexForSet(out, remain.Index)
+		if !fv.IsValid() || !fv.CanSet() {
+			return nil
+		}
+		return d.decodeRemain(path, inputs, used, fv)
+	}
+	return nil
+}
+
+// decodeRemain 将未匹配的key收集到remain字段中，key及value按照remain字段的类型转换
+func (d *decoder) decodeRemain(path string, inputs map[string]reflect.Value, used map[string]bool, remain reflect.Value) error {
+	mt := remain.Type()
+	if remain.IsNil() {
+		remain.Set(reflect.MakeMap(mt))
+	}
+	for k, v := range inputs {
+		if used[k] {
+			continue
+		}
+		kv := reflect.New(mt.Key()).Elem()
+		if err := setValueE(kv, reflect.ValueOf(k), d.registry); err != nil {
+			return fmt.Errorf("Decode %s key failed: %w", path, err)
+		}
+		ev := reflect.New(mt.Elem()).Elem()
+		if err := d.decode(joinPath(path, k), v, ev); err != nil {
+			return err
+		}
+		remain.SetMapIndex(kv, ev)
+	}
+	return nil
+}
+
+func (d *decoder) matchKey(inputs map[string]reflect.Value, name string) (string, bool) {
+	if _, ok := inputs[name]; ok {
+		return name, true
+	}
+	if d.caseInsensitive {
+		// 多个key忽略大小写匹配时选择排序最小的key，保证结果确定
+		match, found := "", false
+		for k := range inputs {
+			if strings.EqualFold(k, name) && (!found || k < match) {
+				match, found = k, true
+			}
+		}
+		return match, found
+	}
+	return "", false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
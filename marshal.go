@@ -0,0 +1,168 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package reflection
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	sqlScannerType      = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// setByUnmarshaler 目标类型实现了encoding.TextUnmarshaler、sql.Scanner或json.Unmarshaler时委托其赋值。
+// 源类型可以直接赋值给目标类型或目标类型为时间类型时不处理（时间类型使用内置的格式解析）
+func setByUnmarshaler(dst, src reflect.Value) (bool, error) {
+	dt := dst.Type()
+	if dt.Kind() == reflect.Interface || dt.ConvertibleTo(TimeType) || src.Type().AssignableTo(dt) {
+		return false, nil
+	}
+	pt := reflect.PtrTo(dt)
+	isText := pt.Implements(textUnmarshalerType)
+	isScanner := pt.Implements(sqlScannerType)
+	isJson := pt.Implements(jsonUnmarshalerType)
+	if !isText && !isScanner && !isJson {
+		return false, nil
+	}
+
+	// 在新值上反序列化，成功后再赋值，失败时不修改dst
+	nv := reflect.New(dt)
+	if isText {
+		if text, ok, err := textOf(src); ok {
+			if err == nil {
+				err = nv.Interface().(encoding.TextUnmarshaler).UnmarshalText(text)
+			}
+			if err != nil {
+				return false, err
+			}
+			dst.Set(nv.Elem())
+			return true, nil
+		}
+	}
+	if isScanner && src.CanInterface() {
+		if err := nv.Interface().(sql.Scanner).Scan(src.Interface()); err != nil {
+			return false, err
+		}
+		dst.Set(nv.Elem())
+		return true, nil
+	}
+	if isJson {
+		if data, ok, err := textOf(src); ok {
+			if err == nil {
+				err = nv.Interface().(json.Unmarshaler).UnmarshalJSON(data)
+			}
+			if err != nil {
+				return false, err
+			}
+			dst.Set(nv.Elem())
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// preferBuiltin 目标为数值、布尔类型且源为数值、布尔类型（未实现encoding.TextMarshaler）时优先使用内置转换规则，
+// 如实现了UnmarshalText的枚举类型从数据库返回的int64赋值，内置规则无法赋值时再委托unmarshaler
+func preferBuiltin(dt reflect.Type, src reflect.Value) bool {
+	if !isScalarKind(dt.Kind()) || !isScalarKind(src.Kind()) {
+		return false
+	}
+	_, ok := receiverOf(src).(encoding.TextMarshaler)
+	return !ok
+}
+
+func isScalarKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// textOf 获得源值的文本：字符串、[]byte、encoding.TextMarshaler以及数值、布尔类型
+func textOf(v reflect.Value) ([]byte, bool, error) {
+	if m, ok := receiverOf(v).(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return b, true, err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return []byte(v.String()), true, nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes(), true, nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return []byte(strconv.FormatInt(v.Int(), 10)), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return []byte(strconv.FormatUint(v.Uint(), 10)), true, nil
+	case reflect.Float32:
+		return []byte(strconv.FormatFloat(v.Float(), 'g', -1, 32)), true, nil
+	case reflect.Float64:
+		return []byte(strconv.FormatFloat(v.Float(), 'g', -1, 64)), true, nil
+	case reflect.Bool:
+		return []byte(strconv.FormatBool(v.Bool())), true, nil
+	}
+	return nil, false, nil
+}
+
+// formatString 将源值格式化为字符串，依次使用fmt.Stringer、encoding.TextMarshaler、driver.Valuer，最后使用fmt.Sprintf("%v")
+func formatString(v reflect.Value) (string, error) {
+	if str, ok, err := formatByInterface(v); ok {
+		return str, err
+	}
+	if !v.CanInterface() {
+		return "", fmt.Errorf("Value of %s cannot interface. ", v.Type())
+	}
+	return fmt.Sprintf("%v", v.Interface()), nil
+}
+
+// formatByInterface 源值实现了fmt.Stringer、encoding.TextMarshaler或driver.Valuer时使用其格式化，否则返回false
+func formatByInterface(v reflect.Value) (string, bool, error) {
+	switch x := receiverOf(v).(type) {
+	case fmt.Stringer:
+		return x.String(), true, nil
+	case encoding.TextMarshaler:
+		b, err := x.MarshalText()
+		return string(b), true, err
+	case driver.Valuer:
+		dv, err := x.Value()
+		if err != nil || dv == nil {
+			return "", true, err
+		}
+		if b, ok := dv.([]byte); ok {
+			return string(b), true, nil
+		}
+		return fmt.Sprintf("%v", dv), true, nil
+	}
+	return "", false, nil
+}
+
+// receiverOf 获得用于调用方法的interface{}，可寻址时使用指针以匹配指针接收者的方法，nil指针返回nil
+func receiverOf(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+	if v.Kind() != reflect.Ptr && v.CanAddr() {
+		if pv := v.Addr(); pv.CanInterface() {
+			return pv.Interface()
+		}
+	}
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package reflection
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy 命名策略，将字段名称转换为映射名称。
+// 必须是幂等的纯函数（结果只取决于输入），只有内置命名策略的解析结果被缓存，自定义命名策略每次重新解析
+type NamingStrategy func(name string) string
+
+// 常见缩写，PascalCase、CamelCase中保持大写
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true,
+	"EOF": true, "GUID": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "QPS": true, "RAM": true, "RPC": true, "SLA": true,
+	"SMTP": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true, "TTL": true,
+	"UDP": true, "UI": true, "UID": true, "UUID": true, "URI": true, "URL": true,
+	"UTF8": true, "VM": true, "XML": true, "XMPP": true, "XSRF": true, "XSS": true,
+}
+
+// SnakeCase 转换为小写下划线分隔，如UserID、userId、user-id均转换为user_id
+func SnakeCase(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "_"))
+}
+
+// KebabCase 转换为小写中划线分隔，如UserID转换为user-id
+func KebabCase(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "-"))
+}
+
+// PascalCase 转换为首字母大写驼峰，常见缩写保持大写，如user_id、userId均转换为UserID
+func PascalCase(name string) string {
+	words := splitWords(name)
+	for i, w := range words {
+		words[i] = pascalWord(w)
+	}
+	return strings.Join(words, "")
+}
+
+// CamelCase 转换为首字母小写驼峰，常见缩写保持大写（位于开头时小写），如user_id转换为userID，URLPath转换为urlPath
+func CamelCase(name string) string {
+	words := splitWords(name)
+	for i, w := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(w)
+		} else {
+			words[i] = pascalWord(w)
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// CaseInsensitive 忽略大小写匹配
+func CaseInsensitive(name string) string {
+	return strings.ToLower(name)
+}
+
+func pascalWord(w string) string {
+	upper := strings.ToUpper(w)
+	if commonInitialisms[upper] {
+		return upper
+	}
+	rs := []rune(strings.ToLower(w))
+	rs[0] = unicode.ToUpper(rs[0])
+	return string(rs)
+}
+
+// splitWords 按分隔符（_、-、空格）以及大小写边界拆分单词，连续大写视为一个单词，如URLPath拆分为URL、Path
+func splitWords(name string) []string {
+	var words []string
+	rs := []rune(name)
+	start := 0
+	for i, r := range rs {
+		if r == '_' || r == '-' || r == ' ' {
+			if i > start {
+				words = append(words, string(rs[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i > start && unicode.IsUpper(r) {
+			prev := rs[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && i+1 < len(rs) && unicode.IsLower(rs[i+1])) {
+				words = append(words, string(rs[start:i]))
+				start = i
+			}
+		}
+	}
+	if start < len(rs) {
+		words = append(words, string(rs[start:]))
+	}
+	return words
+}
+
+// builtinNamings 内置命名策略的函数地址。
+// 函数地址相同的闭包可能捕获不同的状态，因此只有内置命名策略的解析结果被缓存
+var builtinNamings = map[uintptr]bool{
+	reflect.ValueOf(SnakeCase).Pointer():       true,
+	reflect.ValueOf(KebabCase).Pointer():       true,
+	reflect.ValueOf(PascalCase).Pointer():      true,
+	reflect.ValueOf(CamelCase).Pointer():       true,
+	reflect.ValueOf(CaseInsensitive).Pointer(): true,
+}
+
+// namingKey 命名策略在缓存中的标识，非内置命名策略返回false，不缓存
+func namingKey(naming NamingStrategy) (uintptr, bool) {
+	if naming == nil {
+		return 0, true
+	}
+	p := reflect.ValueOf(naming).Pointer()
+	return p, builtinNamings[p]
+}
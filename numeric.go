@@ -0,0 +1,243 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package reflection
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// RoundingPolicy 浮点数转换为整数时的取整策略
+type RoundingPolicy int
+
+const (
+	// RoundTruncate 向零取整
+	RoundTruncate RoundingPolicy = iota
+	// RoundNearest 四舍五入（远离零）
+	RoundNearest
+	// RoundReject 含有小数部分时返回ErrFractional
+	RoundReject
+)
+
+var (
+	// ErrOverflow 数值超出目标类型范围
+	ErrOverflow = errors.New("Value overflow. ")
+	// ErrNegativeToUnsigned 负数不能转换为无符号类型
+	ErrNegativeToUnsigned = errors.New("Negative value cannot convert to unsigned. ")
+	// ErrFractional 浮点数含有小数部分
+	ErrFractional = errors.New("Value has fractional part. ")
+	// ErrPrecisionLoss 整数转换为浮点数时丢失精度
+	ErrPrecisionLoss = errors.New("Value loses precision. ")
+)
+
+// NewCheckedConverterRegistry 创建注册了带检查的数值转换器的注册表
+func NewCheckedConverterRegistry(policy RoundingPolicy) *ConverterRegistry {
+	ret := NewConverterRegistry()
+	ret.RegisterCheckedNumeric(policy)
+	return ret
+}
+
+// RegisterCheckedNumeric 为所有整数、无符号整数、浮点数Kind注册带溢出检查的转换器，会覆盖这些Kind已注册的转换器
+func (r *ConverterRegistry) RegisterCheckedNumeric(policy RoundingPolicy) {
+	f := CheckedNumericConverter(policy)
+	for _, k := range []reflect.Kind{
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64,
+	} {
+		r.RegisterKind(k, f)
+	}
+}
+
+// CheckedNumericConverter 带检查的数值转换器：
+// 1、使用OverflowInt/OverflowUint/OverflowFloat检查溢出；
+// 2、拒绝负数转换为无符号类型；
+// 3、支持浮点数与整数互相转换，浮点数转整数按policy取整；
+// 4、源值为非数值、非字符串类型时不处理，继续使用内置转换规则；
+// 5、目标类型实现了encoding.TextUnmarshaler、sql.Scanner或json.Unmarshaler且源值不是数值、布尔类型时不处理，由其自行解析。
+func CheckedNumericConverter(policy RoundingPolicy) ConverterFunc {
+	return func(dst, src reflect.Value) (bool, error) {
+		if isUnmarshaler(dst.Type()) && !preferBuiltin(dst.Type(), src) {
+			return false, nil
+		}
+		n, ok, err := parseNumber(src)
+		if !ok || err != nil {
+			return ok, err
+		}
+		switch dst.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i, err := n.toInt(policy)
+			if err != nil {
+				return false, err
+			}
+			if dst.OverflowInt(i) {
+				return false, ErrOverflow
+			}
+			dst.SetInt(i)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			u, err := n.toUint(policy)
+			if err != nil {
+				return false, err
+			}
+			if dst.OverflowUint(u) {
+				return false, ErrOverflow
+			}
+			dst.SetUint(u)
+		case reflect.Float32, reflect.Float64:
+			f, err := n.toFloat()
+			if err != nil {
+				return false, err
+			}
+			if dst.OverflowFloat(f) {
+				return false, ErrOverflow
+			}
+			if n.kind != numberFloat && dst.Kind() == reflect.Float32 && float64(float32(f)) != f {
+				return false, ErrPrecisionLoss
+			}
+			dst.SetFloat(f)
+		default:
+			return false, nil
+		}
+		return true, nil
+	}
+}
+
+const (
+	numberInt = iota
+	numberUint
+	numberFloat
+)
+
+type number struct {
+	kind int
+	i    int64
+	u    uint64
+	f    float64
+}
+
+func parseNumber(v reflect.Value) (number, bool, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return number{kind: numberInt, i: v.Int()}, true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return number{kind: numberUint, u: v.Uint()}, true, nil
+	case reflect.Float32, reflect.Float64:
+		return number{kind: numberFloat, f: v.Float()}, true, nil
+	case reflect.String:
+		n, err := parseNumberString(v.String())
+		return n, true, err
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			n, err := parseNumberString(string(v.Bytes()))
+			return n, true, err
+		}
+	}
+	return number{}, false, nil
+}
+
+func parseNumberString(s string) (number, error) {
+	s = strings.TrimSpace(s)
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return number{kind: numberInt, i: i}, nil
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return number{kind: numberUint, u: u}, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return number{}, ErrOverflow
+		}
+		return number{}, err
+	}
+	return number{kind: numberFloat, f: f}, nil
+}
+
+func roundFloat(f float64, policy RoundingPolicy) (float64, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, ErrOverflow
+	}
+	switch policy {
+	case RoundNearest:
+		return math.Round(f), nil
+	case RoundReject:
+		if f != math.Trunc(f) {
+			return 0, ErrFractional
+		}
+		return f, nil
+	default:
+		return math.Trunc(f), nil
+	}
+}
+
+func (n number) toInt(policy RoundingPolicy) (int64, error) {
+	switch n.kind {
+	case numberUint:
+		if n.u > math.MaxInt64 {
+			return 0, ErrOverflow
+		}
+		return int64(n.u), nil
+	case numberFloat:
+		f, err := roundFloat(n.f, policy)
+		if err != nil {
+			return 0, err
+		}
+		// float64(math.MaxInt64) == 2^63
+		if f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, ErrOverflow
+		}
+		return int64(f), nil
+	default:
+		return n.i, nil
+	}
+}
+
+func (n number) toUint(policy RoundingPolicy) (uint64, error) {
+	switch n.kind {
+	case numberInt:
+		if n.i < 0 {
+			return 0, ErrNegativeToUnsigned
+		}
+		return uint64(n.i), nil
+	case numberFloat:
+		f, err := roundFloat(n.f, policy)
+		if err != nil {
+			return 0, err
+		}
+		if f < 0 {
+			return 0, ErrNegativeToUnsigned
+		}
+		// float64(math.MaxUint64) == 2^64
+		if f >= math.MaxUint64 {
+			return 0, ErrOverflow
+		}
+		return uint64(f), nil
+	default:
+		return n.u, nil
+	}
+}
+
+func (n number) toFloat() (float64, error) {
+	switch n.kind {
+	case numberInt:
+		f := float64(n.i)
+		if f >= math.MaxInt64 || int64(f) != n.i {
+			return 0, ErrPrecisionLoss
+		}
+		return f, nil
+	case numberUint:
+		f := float64(n.u)
+		if f >= math.MaxUint64 || uint64(f) != n.u {
+			return 0, ErrPrecisionLoss
+		}
+		return f, nil
+	default:
+		return n.f, nil
+	}
+}
@@ -19,6 +19,7 @@ package reflection
 import (
 	"fmt"
 	"reflect"
+	"sync"
 )
 
 const (
@@ -27,6 +28,9 @@ const (
 	ObjectStruct
 	ObjectSlice
 	ObjectMap
+	ObjectArray
+	ObjectPointer
+	ObjectInterface
 
 	ObjectCustom = 50000
 )
@@ -85,14 +89,40 @@ type StructInfo struct {
 	ClassName string
 	//Model名称（目前用于xml解析是struct的前缀：#{x.username} 中的x）
 	Name string
-	//表字段和实体字段映射关系
+	//表字段和实体字段映射关系，GetReflectStructInfo每次返回新的map，New生成的对象与原对象共享。
+	//SetField优先使用缓存的解析信息匹配，其次使用该map匹配（可以添加别名）
 	FieldNameMap map[string]string
+	//SetField使用的转换器注册表，为nil时使用DefaultConverterRegistry
+	Registry *ConverterRegistry
+	//缓存的结构体解析信息
+	desc *structDescriptor
 
 	Settable
 
 	Newable
 }
 
+// FieldInfo 结构体字段信息，由StructInfo.Fields获得
+type FieldInfo struct {
+	//字段名称
+	Name string
+	//映射名称
+	Alias string
+	//tag选项
+	Options TagOptions
+	//字段索引路径，用于FieldByIndex，不要修改
+	Index []int
+	//字段类型
+	Type reflect.Type
+
+	obj *fieldObject
+}
+
+type fieldObject struct {
+	once sync.Once
+	obj  Object
+}
+
 type SliceInfo struct {
 	//包含pkg的名称
 	ClassName string
@@ -102,6 +132,37 @@ type SliceInfo struct {
 	Newable
 }
 
+type ArrayInfo struct {
+	//包含pkg的名称
+	ClassName string
+	Elem      Object
+	//下一个AddValue设置的下标
+	index int
+
+	Settable
+	Newable
+}
+
+type PointerInfo struct {
+	//包含pkg的名称
+	ClassName string
+	//指针指向的对象，指针为nil时在第一次设置时分配
+	Elem Object
+
+	Settable
+	Newable
+}
+
+type InterfaceInfo struct {
+	//包含pkg的名称
+	ClassName string
+	//动态值对应的对象，在使用时根据动态值解析
+	dynamic Object
+
+	Settable
+	Newable
+}
+
 type SimpleTypeInfo struct {
 	//包含pkg的名称
 	ClassName string
@@ -113,8 +174,12 @@ type SimpleTypeInfo struct {
 type MapInfo struct {
 	//包含pkg的名称
 	ClassName string
+	//key类型
+	KeyType reflect.Type
 	//元素类型
 	ElemType reflect.Type
+	//元素对象，元素类型不能解析为Object时为nil
+	Elem Object
 
 	Settable
 	Newable
@@ -162,6 +227,8 @@ func (structInfo *StructInfo) New() Object {
 		ClassName:    structInfo.ClassName,
 		Name:         structInfo.Name,
 		FieldNameMap: structInfo.FieldNameMap,
+		Registry:     structInfo.Registry,
+		desc:         structInfo.desc,
 	}
 	ret.Type = structInfo.Type
 	ret.Value = reflect.New(structInfo.Type).Elem()
@@ -172,17 +239,70 @@ func (structInfo *StructInfo) NewElem() Object {
 	return nil
 }
 
-func (structInfo *StructInfo) SetField(name string, vv reflect.Value) bool {
-	fieldName := structInfo.FieldNameMap[name]
-	if fieldName != "" {
-		f := structInfo.Value.FieldByName(fieldName)
-		if f.IsValid() {
-			return SetValue(f, vv)
+// Fields 获得字段信息，顺序与结构体定义一致，匿名结构体字段按提升规则展开。
+// 返回值由同类型的所有StructInfo共享，不要修改
+func (structInfo *StructInfo) Fields() []FieldInfo {
+	if structInfo.desc == nil {
+		return nil
+	}
+	return structInfo.desc.fieldInfos()
+}
+
+// Object 获得字段类型对应的Object，类型不支持时返回nil。
+// 类型解析结果按结构体缓存，每次调用返回新生成的对象，使用ResetValue关联到字段值
+func (fieldInfo *FieldInfo) Object() Object {
+	if fieldInfo.obj == nil {
+		return nil
+	}
+	fieldInfo.obj.once.Do(func() {
+		obj, err := GetReflectObjectInfo(fieldInfo.Type, reflect.New(fieldInfo.Type).Elem())
+		if err == nil {
+			fieldInfo.obj.obj = obj
 		}
+	})
+	if fieldInfo.obj.obj == nil {
+		return nil
+	}
+	return fieldInfo.obj.obj.New()
+}
+
+func (structInfo *StructInfo) SetField(name string, vv reflect.Value) bool {
+	f := structInfo.fieldValue(name, true)
+	if f.IsValid() {
+		return setValue(f, vv, selectRegistry([]*ConverterRegistry{structInfo.Registry}))
 	}
 	return false
 }
 
+// fieldValue 根据映射名称获得字段值，使用缓存的索引路径访问。
+// forSet为true时分配路径上的nil嵌入指针，否则遇到nil嵌入指针返回无效值
+func (structInfo *StructInfo) fieldValue(name string, forSet bool) reflect.Value {
+	if !structInfo.Value.IsValid() {
+		return reflect.Value{}
+	}
+	var index []int
+	if structInfo.desc != nil {
+		if f := structInfo.desc.field(name); f != nil {
+			index = f.Index
+		}
+	}
+	if index == nil {
+		fieldName := structInfo.FieldNameMap[name]
+		if fieldName == "" {
+			return reflect.Value{}
+		}
+		sf, ok := structInfo.Value.Type().FieldByName(fieldName)
+		if !ok {
+			return reflect.Value{}
+		}
+		index = sf.Index
+	}
+	if forSet {
+		return fieldByIndexForSet(structInfo.Value, index)
+	}
+	return fieldByIndexForGet(structInfo.Value, index)
+}
+
 func (structInfo *StructInfo) AddValue(v reflect.Value) bool {
 	return false
 }
@@ -297,21 +417,22 @@ func (simpleTypeInfo *SimpleTypeInfo) CanAddValue() bool {
 	return false
 }
 
+// CanSet v的类型可以直接赋值（或转换）为map类型时返回true，如map[string]int不能赋值给map[string]interface{}
 func (mapInfo *MapInfo) CanSet(v reflect.Value) bool {
-	if mapInfo.Value.Kind() != v.Kind() {
+	if !v.IsValid() || v.Kind() != reflect.Map {
 		return false
 	}
 	rt := v.Type()
-	if rt.Key().Kind() != reflect.String || rt.Elem().Kind() != reflect.Interface {
-		return false
-	}
-	return true
+	return rt.AssignableTo(mapInfo.Type) || rt.ConvertibleTo(mapInfo.Type)
 }
 
-// SetValue TODO: 目前仅支持map[string]interface{}，需增加其他类型支持
 func (mapInfo *MapInfo) SetValue(v reflect.Value) bool {
 	if mapInfo.CanSet(v) {
-		mapInfo.Value.Set(v)
+		if v.Type().AssignableTo(mapInfo.Type) {
+			mapInfo.Value.Set(v)
+		} else {
+			mapInfo.Value.Set(v.Convert(mapInfo.Type))
+		}
 		return true
 	}
 	return false
@@ -320,24 +441,51 @@ func (mapInfo *MapInfo) SetValue(v reflect.Value) bool {
 func (mapInfo *MapInfo) New() Object {
 	ret := &MapInfo{
 		ClassName: mapInfo.ClassName,
+		KeyType:   mapInfo.KeyType,
 		ElemType:  mapInfo.ElemType,
 	}
+	if mapInfo.Elem != nil {
+		ret.Elem = mapInfo.Elem.New()
+	}
+	ret.Type = mapInfo.Type
 	ret.Value = reflect.New(mapInfo.Type).Elem()
 	return ret
 }
 
-// NewElem FIXME: return nil，需要对map元素解析
+// NewElem 获得新的元素对象，元素类型不能解析为Object时返回nil
 func (mapInfo *MapInfo) NewElem() Object {
-	return nil
+	if mapInfo.Elem == nil {
+		return nil
+	}
+	return mapInfo.Elem.New()
 }
 
+// SetField 按SetValue的规则将name转换为key类型、vv转换为元素类型后设置，map为nil时创建新map
 func (mapInfo *MapInfo) SetField(name string, vv reflect.Value) bool {
+	k := reflect.New(mapInfo.KeyType).Elem()
+	if !SetValue(k, reflect.ValueOf(name)) {
+		return false
+	}
 	v := reflect.New(mapInfo.ElemType).Elem()
-	if SetValue(v, vv) {
-		mapInfo.Value.SetMapIndex(reflect.ValueOf(name), v)
-		return true
+	if !SetValue(v, vv) {
+		return false
 	}
-	return false
+	if mapInfo.Value.IsNil() {
+		if !mapInfo.Value.CanSet() {
+			return false
+		}
+		mapInfo.Value.Set(reflect.MakeMap(mapInfo.Type))
+	}
+	mapInfo.Value.SetMapIndex(k, v)
+	return true
+}
+
+// SetFieldObject 将元素对象的值设置到key中，通常先通过NewElem获得元素对象并填充字段，再调用该方法写入map
+func (mapInfo *MapInfo) SetFieldObject(key string, elem Object) bool {
+	if elem == nil {
+		return false
+	}
+	return mapInfo.SetField(key, elem.GetValue())
 }
 
 func (mapInfo *MapInfo) AddValue(v reflect.Value) bool {
@@ -361,6 +509,251 @@ func (mapInfo *MapInfo) GetClassName() string {
 	return mapInfo.ClassName
 }
 
+func (arrayInfo *ArrayInfo) New() Object {
+	ret := &ArrayInfo{
+		ClassName: arrayInfo.ClassName,
+		Elem:      arrayInfo.Elem.New(),
+	}
+	ret.Type = arrayInfo.Type
+	ret.Value = reflect.New(arrayInfo.Type).Elem()
+	return ret
+}
+
+func (arrayInfo *ArrayInfo) NewElem() Object {
+	return arrayInfo.Elem.New()
+}
+
+func (arrayInfo *ArrayInfo) SetField(name string, v reflect.Value) bool {
+	return false
+}
+
+// AddValue 按顺序设置元素，数组已满时返回false
+func (arrayInfo *ArrayInfo) AddValue(v reflect.Value) bool {
+	if arrayInfo.index >= arrayInfo.Value.Len() || !arrayInfo.Elem.CanSet(v) {
+		return false
+	}
+	// 按SetValue的规则转换，失败时不占用下标
+	if !SetValue(arrayInfo.Value.Index(arrayInfo.index), v) {
+		return false
+	}
+	arrayInfo.index++
+	return true
+}
+
+// ResetValue 变换value对象，AddValue从第一个元素重新开始
+func (arrayInfo *ArrayInfo) ResetValue(v reflect.Value) {
+	arrayInfo.Value = v
+	arrayInfo.index = 0
+}
+
+func (arrayInfo *ArrayInfo) GetClassName() string {
+	return arrayInfo.ClassName
+}
+
+func (arrayInfo *ArrayInfo) Kind() int {
+	return ObjectArray
+}
+
+func (arrayInfo *ArrayInfo) CanSetField() bool {
+	return false
+}
+
+func (arrayInfo *ArrayInfo) CanAddValue() bool {
+	return true
+}
+
+func (pointerInfo *PointerInfo) New() Object {
+	ret := &PointerInfo{
+		ClassName: pointerInfo.ClassName,
+		Elem:      pointerInfo.Elem.New(),
+	}
+	ret.Type = pointerInfo.Type
+	ret.Value = reflect.New(pointerInfo.Type).Elem()
+	return ret
+}
+
+// elem 获得指向的对象，指针为nil时分配新值
+func (pointerInfo *PointerInfo) elem() Object {
+	if pointerInfo.Value.IsNil() {
+		if !pointerInfo.Value.CanSet() {
+			return nil
+		}
+		pointerInfo.Value.Set(reflect.New(pointerInfo.Type.Elem()))
+	}
+	pv := pointerInfo.Value.Elem()
+	if ev := pointerInfo.Elem.GetValue(); !ev.IsValid() || !ev.CanAddr() || ev.UnsafeAddr() != pv.UnsafeAddr() {
+		pointerInfo.Elem.ResetValue(pv)
+	}
+	return pointerInfo.Elem
+}
+
+func (pointerInfo *PointerInfo) NewElem() Object {
+	return pointerInfo.Elem.NewElem()
+}
+
+func (pointerInfo *PointerInfo) SetField(name string, v reflect.Value) bool {
+	if o := pointerInfo.elem(); o != nil {
+		return o.SetField(name, v)
+	}
+	return false
+}
+
+func (pointerInfo *PointerInfo) AddValue(v reflect.Value) bool {
+	if o := pointerInfo.elem(); o != nil {
+		return o.AddValue(v)
+	}
+	return false
+}
+
+// CanSet v可以直接赋值给该指针类型时返回true，SliceInfo、ArrayInfo等容器据此直接添加元素
+func (pointerInfo *PointerInfo) CanSet(v reflect.Value) bool {
+	return v.IsValid() && v.Type().AssignableTo(pointerInfo.Type)
+}
+
+// SetValue v为同类型指针时直接赋值，否则设置到指向的对象中（指针为nil时分配新值）
+func (pointerInfo *PointerInfo) SetValue(v reflect.Value) bool {
+	if v.IsValid() && v.Type().AssignableTo(pointerInfo.Type) {
+		pointerInfo.Value.Set(v)
+		return true
+	}
+	if o := pointerInfo.elem(); o != nil {
+		return o.SetValue(v)
+	}
+	return false
+}
+
+func (pointerInfo *PointerInfo) GetClassName() string {
+	return pointerInfo.ClassName
+}
+
+func (pointerInfo *PointerInfo) Kind() int {
+	return ObjectPointer
+}
+
+func (pointerInfo *PointerInfo) CanSetField() bool {
+	return pointerInfo.Elem.CanSetField()
+}
+
+func (pointerInfo *PointerInfo) CanAddValue() bool {
+	return pointerInfo.Elem.CanAddValue()
+}
+
+func (interfaceInfo *InterfaceInfo) New() Object {
+	ret := &InterfaceInfo{
+		ClassName: interfaceInfo.ClassName,
+	}
+	ret.Type = interfaceInfo.Type
+	ret.Value = reflect.New(interfaceInfo.Type).Elem()
+	return ret
+}
+
+// elem 根据动态值解析对象，动态值不可寻址时解析其副本，修改后需要通过writeBack写回
+func (interfaceInfo *InterfaceInfo) elem() Object {
+	if !interfaceInfo.Value.IsValid() || interfaceInfo.Value.IsNil() {
+		return nil
+	}
+	dv := interfaceInfo.Value.Elem()
+	if d := interfaceInfo.dynamic; d != nil && d.GetValue().Type() == dv.Type() {
+		if dv.Kind() == reflect.Ptr {
+			// 动态值指向其他对象时重新关联
+			if d.GetValue().Pointer() != dv.Pointer() {
+				d.ResetValue(dv)
+			}
+		} else {
+			// 动态值可能已在外部修改，同步到副本
+			d.GetValue().Set(dv)
+		}
+		return d
+	}
+	if dv.Kind() != reflect.Ptr {
+		cp := reflect.New(dv.Type()).Elem()
+		cp.Set(dv)
+		dv = cp
+	}
+	o, err := GetReflectObjectInfo(dv.Type(), dv)
+	if err != nil {
+		return nil
+	}
+	interfaceInfo.dynamic = o
+	return o
+}
+
+func (interfaceInfo *InterfaceInfo) writeBack(o Object) {
+	if v := o.GetValue(); v.Kind() != reflect.Ptr {
+		interfaceInfo.Value.Set(v)
+	}
+}
+
+// NewElem 获得动态值的元素对象，没有动态值时返回nil
+func (interfaceInfo *InterfaceInfo) NewElem() Object {
+	if o := interfaceInfo.elem(); o != nil {
+		return o.NewElem()
+	}
+	return nil
+}
+
+func (interfaceInfo *InterfaceInfo) SetField(name string, v reflect.Value) bool {
+	o := interfaceInfo.elem()
+	if o == nil || !o.SetField(name, v) {
+		return false
+	}
+	interfaceInfo.writeBack(o)
+	return true
+}
+
+func (interfaceInfo *InterfaceInfo) AddValue(v reflect.Value) bool {
+	o := interfaceInfo.elem()
+	if o == nil || !o.AddValue(v) {
+		return false
+	}
+	interfaceInfo.writeBack(o)
+	return true
+}
+
+// CanSet v的类型实现了该接口时返回true
+func (interfaceInfo *InterfaceInfo) CanSet(v reflect.Value) bool {
+	return v.IsValid() && v.Type().AssignableTo(interfaceInfo.Type)
+}
+
+func (interfaceInfo *InterfaceInfo) SetValue(v reflect.Value) bool {
+	if !interfaceInfo.CanSet(v) {
+		return false
+	}
+	interfaceInfo.Value.Set(v)
+	interfaceInfo.dynamic = nil
+	return true
+}
+
+// ResetValue 变换value对象，动态值重新解析
+func (interfaceInfo *InterfaceInfo) ResetValue(v reflect.Value) {
+	interfaceInfo.Value = v
+	interfaceInfo.dynamic = nil
+}
+
+func (interfaceInfo *InterfaceInfo) GetClassName() string {
+	return interfaceInfo.ClassName
+}
+
+func (interfaceInfo *InterfaceInfo) Kind() int {
+	return ObjectInterface
+}
+
+// CanSetField 动态值可以设置字段时返回true
+func (interfaceInfo *InterfaceInfo) CanSetField() bool {
+	if o := interfaceInfo.elem(); o != nil {
+		return o.CanSetField()
+	}
+	return false
+}
+
+// CanAddValue 动态值可以添加元素时返回true
+func (interfaceInfo *InterfaceInfo) CanAddValue() bool {
+	if o := interfaceInfo.elem(); o != nil {
+		return o.CanAddValue()
+	}
+	return false
+}
+
 func GetObjectInfo(model interface{}) (Object, error) {
 	rt := reflect.TypeOf(model)
 	rv := reflect.ValueOf(model)
@@ -375,7 +768,16 @@ func GetObjectInfo(model interface{}) (Object, error) {
 	return GetReflectObjectInfo(rt, rv)
 }
 
+// GetReflectObjectInfo 解析类型对应的Object，优先使用DefaultObjectFactoryRegistry中注册的自定义构造函数
 func GetReflectObjectInfo(rt reflect.Type, rv reflect.Value) (Object, error) {
+	return getReflectObjectInfo(rt, rv, nil)
+}
+
+// getReflectObjectInfo resolving为正在解析的容器类型，用于检测递归类型
+func getReflectObjectInfo(rt reflect.Type, rv reflect.Value, resolving map[reflect.Type]bool) (Object, error) {
+	if factory := DefaultObjectFactoryRegistry.Lookup(rt); factory != nil {
+		return factory(rt, rv)
+	}
 	if IsSimpleType(rt) {
 		return GetReflectSimpleTypeInfo(rt, rv)
 	}
@@ -383,9 +785,15 @@ func GetReflectObjectInfo(rt reflect.Type, rv reflect.Value) (Object, error) {
 	case reflect.Struct:
 		return GetReflectStructInfo(rt, rv)
 	case reflect.Slice:
-		return GetReflectSliceInfo(rt, rv)
+		return getReflectSliceInfo(rt, rv, resolving)
 	case reflect.Map:
-		return GetReflectMapInfo(rt, rv)
+		return getReflectMapInfo(rt, rv, resolving)
+	case reflect.Array:
+		return getReflectArrayInfo(rt, rv, resolving)
+	case reflect.Ptr:
+		return getReflectPointerInfo(rt, rv, resolving)
+	case reflect.Interface:
+		return GetReflectInterfaceInfo(rt, rv)
 	}
 	return nil, fmt.Errorf("Type %s not support ", rt.String())
 }
@@ -404,6 +812,10 @@ func GetReflectSimpleTypeInfo(rt reflect.Type, rv reflect.Value) (Object, error)
 }
 
 func GetReflectSliceInfo(rt reflect.Type, rv reflect.Value) (Object, error) {
+	return getReflectSliceInfo(rt, rv, nil)
+}
+
+func getReflectSliceInfo(rt reflect.Type, rv reflect.Value, resolving map[reflect.Type]bool) (Object, error) {
 	if rt.Kind() == reflect.Ptr {
 		rt = rt.Elem()
 		rv = rv.Elem()
@@ -414,11 +826,17 @@ func GetReflectSliceInfo(rt reflect.Type, rv reflect.Value) (Object, error) {
 	if kind != reflect.Slice {
 		return nil, fmt.Errorf("Type %s is not slice type ", rt.String())
 	}
+	resolving, err := enterResolving(resolving, rt)
+	if err != nil {
+		return nil, err
+	}
+	defer delete(resolving, rt)
+
 	//获得元素类型
 	et := rt.Elem()
 	ev := reflect.New(et).Elem()
 
-	elemObj, err := GetReflectObjectInfo(et, ev)
+	elemObj, err := getReflectObjectInfo(et, ev, resolving)
 	if err != nil {
 		return nil, err
 	}
@@ -431,7 +849,102 @@ func GetReflectSliceInfo(rt reflect.Type, rv reflect.Value) (Object, error) {
 	return &ret, nil
 }
 
+func GetReflectArrayInfo(rt reflect.Type, rv reflect.Value) (Object, error) {
+	return getReflectArrayInfo(rt, rv, nil)
+}
+
+func getReflectArrayInfo(rt reflect.Type, rv reflect.Value, resolving map[reflect.Type]bool) (Object, error) {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+		rv = rv.Elem()
+	}
+
+	kind := rt.Kind()
+
+	if kind != reflect.Array {
+		return nil, fmt.Errorf("Type %s is not array type ", rt.String())
+	}
+	resolving, err := enterResolving(resolving, rt)
+	if err != nil {
+		return nil, err
+	}
+	defer delete(resolving, rt)
+
+	//获得元素类型
+	et := rt.Elem()
+	ev := reflect.New(et).Elem()
+
+	elemObj, err := getReflectObjectInfo(et, ev, resolving)
+	if err != nil {
+		return nil, err
+	}
+	if elemObj.CanAddValue() {
+		return nil, fmt.Errorf("Elem  cannot add value ")
+	}
+	ret := ArrayInfo{Elem: elemObj, ClassName: GetTypeClassName(rt)}
+	ret.Type = rt
+	ret.Value = rv
+	return &ret, nil
+}
+
+// enterResolving 将rt标记为正在解析，rt已在解析中（递归类型）时返回错误
+func enterResolving(resolving map[reflect.Type]bool, rt reflect.Type) (map[reflect.Type]bool, error) {
+	if resolving[rt] {
+		return resolving, fmt.Errorf("Type %s is recursive, not support ", rt.String())
+	}
+	if resolving == nil {
+		resolving = map[reflect.Type]bool{}
+	}
+	resolving[rt] = true
+	return resolving, nil
+}
+
+// GetReflectPointerInfo 解析指针类型，rt必须是指针类型，指向的对象通过GetReflectObjectInfo解析
+func GetReflectPointerInfo(rt reflect.Type, rv reflect.Value) (Object, error) {
+	return getReflectPointerInfo(rt, rv, nil)
+}
+
+func getReflectPointerInfo(rt reflect.Type, rv reflect.Value, resolving map[reflect.Type]bool) (Object, error) {
+	if rt.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("Type %s is not pointer type ", rt.String())
+	}
+	resolving, err := enterResolving(resolving, rt)
+	if err != nil {
+		return nil, err
+	}
+	defer delete(resolving, rt)
+
+	et := rt.Elem()
+	ev := reflect.New(et).Elem()
+	if rv.IsValid() && !rv.IsNil() {
+		ev = rv.Elem()
+	}
+	elemObj, err := getReflectObjectInfo(et, ev, resolving)
+	if err != nil {
+		return nil, err
+	}
+	ret := PointerInfo{Elem: elemObj, ClassName: GetTypeClassName(rt)}
+	ret.Type = rt
+	ret.Value = rv
+	return &ret, nil
+}
+
+// GetReflectInterfaceInfo 解析接口类型，动态值对应的对象在使用时解析
+func GetReflectInterfaceInfo(rt reflect.Type, rv reflect.Value) (Object, error) {
+	if rt.Kind() != reflect.Interface {
+		return nil, fmt.Errorf("Type %s is not interface type ", rt.String())
+	}
+	ret := InterfaceInfo{ClassName: GetTypeClassName(rt)}
+	ret.Type = rt
+	ret.Value = rv
+	return &ret, nil
+}
+
 func GetReflectMapInfo(rt reflect.Type, rv reflect.Value) (Object, error) {
+	return getReflectMapInfo(rt, rv, nil)
+}
+
+func getReflectMapInfo(rt reflect.Type, rv reflect.Value, resolving map[reflect.Type]bool) (Object, error) {
 	if rt.Kind() == reflect.Ptr {
 		rt = rt.Elem()
 		rv = rv.Elem()
@@ -443,21 +956,39 @@ func GetReflectMapInfo(rt reflect.Type, rv reflect.Value) (Object, error) {
 		return nil, fmt.Errorf("Type %s is not map ", rt)
 	}
 
-	if rt.Key().Kind() != reflect.String {
-		return nil, fmt.Errorf("Map key must string but get %s ", rt.Key())
+	if !isStringKeyType(rt.Key()) {
+		return nil, fmt.Errorf("Map key must be convertible from string but get %s ", rt.Key())
 	}
 
-	//TODO: 目前仅支持map[string]interface{}，需增加其他类型支持
-	if rt.Elem().Kind() != reflect.Interface {
-		return nil, fmt.Errorf("Map type support map[string]interface{} only, but get map[%v]%v \n", rt.Key(), rt.Elem())
+	resolving, err := enterResolving(resolving, rt)
+	if err != nil {
+		return nil, err
 	}
+	defer delete(resolving, rt)
 
-	ret := MapInfo{ElemType: rt.Elem(), ClassName: GetTypeClassName(rt)}
+	ret := MapInfo{KeyType: rt.Key(), ElemType: rt.Elem(), ClassName: GetTypeClassName(rt)}
+	//获得元素类型，递归类型（如type M map[string]M）的元素不解析
+	et := rt.Elem()
+	if elemObj, err := getReflectObjectInfo(et, reflect.New(et).Elem(), resolving); err == nil {
+		ret.Elem = elemObj
+	}
 	ret.Type = rt
 	ret.Value = rv
 	return &ret, nil
 }
 
+// isStringKeyType map key类型是否可以由string转换：string、数值、bool以及实现了encoding.TextUnmarshaler的类型
+func isStringKeyType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
 //GetStructInfo 解析结构体，使用：
 //1、如果结构体中含有gobatis.ModelName类型的字段，则：
 // a)、如果含有tag，则使用tag作为tablename；
@@ -472,10 +1003,19 @@ func GetStructInfo(bean interface{}) (*StructInfo, error) {
 	return GetReflectStructInfo(reflect.TypeOf(bean), reflect.ValueOf(bean))
 }
 
+// GetReflectStructInfo 获得结构体信息，aliasTag为按优先级排列的映射名称tag，
+// 如GetReflectStructInfo(t, v, "db", "json")优先使用db，其次使用json，都没有时使用字段名称，tag名称为‘-’的字段忽略。
+// 默认使用StructAliasTag
 func GetReflectStructInfo(rt reflect.Type, rv reflect.Value, aliasTag ...string) (*StructInfo, error) {
-	tag := StructAliasTag
-	if len(aliasTag) > 0 {
-		tag = aliasTag[0]
+	return GetReflectStructInfoEx(rt, rv, nil, aliasTag...)
+}
+
+// GetReflectStructInfoEx 同GetReflectStructInfo，naming不为nil时没有tag名称的字段使用naming转换后的字段名称作为映射名称，
+// SetField时优先精确匹配映射名称，其次按naming归一化后匹配，如使用SnakeCase时user_name、UserName均匹配UserName字段
+func GetReflectStructInfoEx(rt reflect.Type, rv reflect.Value, naming NamingStrategy, aliasTag ...string) (*StructInfo, error) {
+	tags := aliasTag
+	if len(tags) == 0 {
+		tags = []string{StructAliasTag}
 	}
 	if rt.Kind() == reflect.Ptr {
 		rt = rt.Elem()
@@ -489,48 +1029,18 @@ func GetReflectStructInfo(rt reflect.Type, rv reflect.Value, aliasTag ...string)
 	if kind != reflect.Struct {
 		return nil, fmt.Errorf("Type %s is not struct ", rt)
 	}
+	desc := getStructDescriptor(rt, tags, naming, false)
 	objInfo := StructInfo{
-		FieldNameMap: map[string]string{},
+		ClassName:    desc.ClassName,
+		Name:         desc.Name,
+		FieldNameMap: make(map[string]string, len(desc.FieldNameMap)),
+		desc:         desc,
+	}
+	for k, v := range desc.FieldNameMap {
+		objInfo.FieldNameMap[k] = v
 	}
 	objInfo.Type = rt
 	objInfo.Value = rv
-	//Default name is struct name
-	objInfo.Name = rt.Name()
-	objInfo.ClassName = GetTypeClassName(rt)
-
-	//字段解析
-	for i, j := 0, rt.NumField(); i < j; i++ {
-		rtf := rt.Field(i)
-
-		//if rtf.Type == modelNameType {
-		//    if rtf.Tag != "" {
-		//        objInfo.Name = string(rtf.Tag)
-		//    } else {
-		//        objInfo.Name = rtf.Name
-		//    }
-		//    continue
-		//}
-
-		//没有tag,表字段名与实体字段名一致
-		if rtf.Tag == "" {
-			objInfo.FieldNameMap[rtf.Name] = rtf.Name
-			continue
-		}
-
-		if rtf.Tag == "-" {
-			continue
-		}
-
-		fieldName := rtf.Name
-		tagName := rtf.Tag.Get(tag)
-		if tagName == "-" {
-			continue
-		} else if tagName != "" {
-			fieldName = tagName
-		}
-		objInfo.FieldNameMap[fieldName] = rtf.Name
-		continue
-	}
 	return &objInfo, nil
 }
 
@@ -540,11 +1050,12 @@ func (structInfo *StructInfo) MapValue() map[string]interface{} {
 	return paramMap
 }
 
+// FillMapValue 将顶层字段值填充到map中，未导出的字段忽略。递归转换请使用Encode
 func (structInfo *StructInfo) FillMapValue(paramMap *map[string]interface{}) {
-	for k, v := range structInfo.FieldNameMap {
-		f := structInfo.Value.FieldByName(v)
-		if !f.CanInterface() {
-			f = reflect.Indirect(f)
+	for k := range structInfo.FieldNameMap {
+		f := structInfo.fieldValue(k, false)
+		if !f.IsValid() || !f.CanInterface() {
+			continue
 		}
 		(*paramMap)[k] = f.Interface()
 	}
@@ -0,0 +1,128 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package reflection
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// ObjectFactory 自定义Object构造函数，返回的Object的Kind应不小于ObjectCustom
+type ObjectFactory func(rt reflect.Type, rv reflect.Value) (Object, error)
+
+type objectFactoryEntry struct {
+	match    func(reflect.Type) bool
+	factory  ObjectFactory
+	priority int
+	seq      int
+}
+
+// ObjectFactoryRegistry 自定义Object注册表，GetReflectObjectInfo在内置解析规则之前查询。
+// 注册应在使用前（如init中）完成，已缓存的结构体解析信息不会随注册更新
+type ObjectFactoryRegistry struct {
+	lock          sync.RWMutex
+	factories     []objectFactoryEntry
+	typeFactories map[reflect.Type]ObjectFactory
+	simpleTypes   map[reflect.Type]bool
+	seq           int
+}
+
+// DefaultObjectFactoryRegistry 默认自定义Object注册表，GetReflectObjectInfo、IsSimpleType使用
+var DefaultObjectFactoryRegistry = NewObjectFactoryRegistry()
+
+func NewObjectFactoryRegistry() *ObjectFactoryRegistry {
+	return &ObjectFactoryRegistry{
+		typeFactories: map[reflect.Type]ObjectFactory{},
+		simpleTypes:   map[reflect.Type]bool{},
+	}
+}
+
+// RegisterObjectFactory 向默认注册表注册自定义Object构造函数，match返回true的类型使用factory构造，
+// priority越大越先匹配（默认为0），相同优先级后注册的先匹配
+func RegisterObjectFactory(match func(reflect.Type) bool, factory ObjectFactory, priority ...int) {
+	DefaultObjectFactoryRegistry.Register(match, factory, priority...)
+}
+
+// RegisterTypeObjectFactory 向默认注册表注册指定类型的自定义Object构造函数
+func RegisterTypeObjectFactory(t reflect.Type, factory ObjectFactory) {
+	DefaultObjectFactoryRegistry.RegisterType(t, factory)
+}
+
+// RegisterSimpleType 向默认注册表声明简单类型，IsSimpleType对这些类型返回true
+func RegisterSimpleType(types ...reflect.Type) {
+	DefaultObjectFactoryRegistry.RegisterSimpleType(types...)
+}
+
+// Register 注册自定义Object构造函数，match或factory为nil时忽略
+func (r *ObjectFactoryRegistry) Register(match func(reflect.Type) bool, factory ObjectFactory, priority ...int) {
+	if match == nil || factory == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.seq++
+	entry := objectFactoryEntry{match: match, factory: factory, seq: r.seq}
+	if len(priority) > 0 {
+		entry.priority = priority[0]
+	}
+	r.factories = append(r.factories, entry)
+	sort.SliceStable(r.factories, func(i, j int) bool {
+		if r.factories[i].priority != r.factories[j].priority {
+			return r.factories[i].priority > r.factories[j].priority
+		}
+		return r.factories[i].seq > r.factories[j].seq
+	})
+}
+
+// RegisterType 注册指定类型的自定义Object构造函数，优先级高于Register注册的构造函数，factory为nil时删除
+func (r *ObjectFactoryRegistry) RegisterType(t reflect.Type, factory ObjectFactory) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if factory == nil {
+		delete(r.typeFactories, t)
+	} else {
+		r.typeFactories[t] = factory
+	}
+}
+
+// RegisterSimpleType 声明简单类型
+func (r *ObjectFactoryRegistry) RegisterSimpleType(types ...reflect.Type) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, t := range types {
+		r.simpleTypes[t] = true
+	}
+}
+
+// Lookup 查找类型的自定义Object构造函数，先匹配指定类型，再按优先级匹配
+func (r *ObjectFactoryRegistry) Lookup(t reflect.Type) ObjectFactory {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if len(r.typeFactories) > 0 {
+		if f, ok := r.typeFactories[t]; ok {
+			return f
+		}
+	}
+	for _, e := range r.factories {
+		if e.match(t) {
+			return e.factory
+		}
+	}
+	return nil
+}
+
+// IsSimpleType 类型是否被声明为简单类型
+func (r *ObjectFactoryRegistry) IsSimpleType(t reflect.Type) bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.simpleTypes[t]
+}
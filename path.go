@@ -0,0 +1,471 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package reflection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// 结构体字段：Name
+	segmentField = iota
+	// 下标或map key：[3]、["env"]、[env]
+	segmentIndex
+	// 在slice末尾添加元素：[]、[-]
+	segmentAppend
+)
+
+type pathSegment struct {
+	kind int
+	// 字段名称或[]中的内容（已去除引号）
+	name string
+	// []中的内容是否带引号，带引号时只能作为map key
+	quoted bool
+}
+
+func (s pathSegment) String() string {
+	switch s.kind {
+	case segmentIndex:
+		if s.quoted {
+			return "[" + strconv.Quote(s.name) + "]"
+		}
+		return "[" + s.name + "]"
+	case segmentAppend:
+		return "[]"
+	}
+	return s.name
+}
+
+// parsePath 解析字段路径，语法见SetFieldValueEx
+func parsePath(path string) ([]pathSegment, error) {
+	var ret []pathSegment
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+		case '[':
+			seg, n, err := parseBracket(path[i:])
+			if err != nil {
+				return nil, fmt.Errorf("Path %s is invalid at %d: %w", path, i, err)
+			}
+			ret = append(ret, seg)
+			i += n
+		case ']':
+			return nil, fmt.Errorf("Path %s is invalid at %d: %w", path, i, ErrInvalidPath)
+		default:
+			j := i
+			for j < len(path) && path[j] != '.' && path[j] != '[' && path[j] != ']' {
+				j++
+			}
+			if name := strings.TrimSpace(path[i:j]); name != "" {
+				ret = append(ret, pathSegment{kind: segmentField, name: name})
+			}
+			i = j
+		}
+	}
+	return ret, nil
+}
+
+// parseBracket 解析以‘[’开头的片段，返回片段以及消耗的长度
+func parseBracket(s string) (pathSegment, int, error) {
+	i := 1
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	if i < len(s) && (s[i] == '"' || s[i] == '`') {
+		quote := s[i]
+		j := i + 1
+		for ; j < len(s) && s[j] != quote; j++ {
+			if s[j] == '\\' && quote == '"' {
+				j++
+			}
+		}
+		if j >= len(s) {
+			return pathSegment{}, 0, ErrInvalidPath
+		}
+		key, err := strconv.Unquote(s[i : j+1])
+		if err != nil {
+			return pathSegment{}, 0, err
+		}
+		j++
+		for j < len(s) && s[j] == ' ' {
+			j++
+		}
+		if j >= len(s) || s[j] != ']' {
+			return pathSegment{}, 0, ErrInvalidPath
+		}
+		return pathSegment{kind: segmentIndex, name: key, quoted: true}, j + 1, nil
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end == -1 {
+		return pathSegment{}, 0, ErrInvalidPath
+	}
+	content := strings.TrimSpace(s[1:end])
+	if content == "" || content == "-" {
+		return pathSegment{kind: segmentAppend}, end + 1, nil
+	}
+	return pathSegment{kind: segmentIndex, name: content}, end + 1, nil
+}
+
+// PathOption 配置路径读写
+type PathOption func(o *pathOptions)
+
+type pathOptions struct {
+	tagNames   []string
+	naming     NamingStrategy
+	modifier   func(string) string
+	registry   *ConverterRegistry
+	autoCreate bool
+	unexported bool
+}
+
+// WithTagName 按照tag名称匹配字段，没有该tag名称的字段匹配字段名称，默认只匹配字段名称
+func WithTagName(tagName string) PathOption {
+	return WithTagNames(tagName)
+}
+
+// WithTagNames 按照tag名称匹配字段，tagNames按优先级排列，如WithTagNames("db", "json")，
+// 都没有tag名称的字段匹配字段名称，tag名称为‘-’的字段忽略
+func WithTagNames(tagNames ...string) PathOption {
+	return func(o *pathOptions) {
+		o.tagNames = tagNames
+	}
+}
+
+// WithNamingStrategy 没有tag名称的字段使用naming转换后的字段名称匹配，路径中的名称不能精确匹配时按naming归一化后匹配，
+// 如WithNamingStrategy(SnakeCase)时user_name、UserName均匹配UserName字段
+func WithNamingStrategy(naming NamingStrategy) PathOption {
+	return func(o *pathOptions) {
+		o.naming = naming
+	}
+}
+
+// WithModifier 匹配前先使用modifier修改路径中的字段名称
+func WithModifier(modifier func(string) string) PathOption {
+	return func(o *pathOptions) {
+		o.modifier = modifier
+	}
+}
+
+// WithRegistry 指定赋值时使用的转换器注册表，默认为DefaultConverterRegistry
+func WithRegistry(registry *ConverterRegistry) PathOption {
+	return func(o *pathOptions) {
+		o.registry = registry
+	}
+}
+
+// WithAutoCreate 设置值时自动创建路径上的中间值：
+// 分配nil指针（包括nil嵌入结构体指针），创建nil map，创建不存在的map元素，下标越界时扩展slice
+func WithAutoCreate() PathOption {
+	return func(o *pathOptions) {
+		o.autoCreate = true
+	}
+}
+
+// AllowUnexported 允许通过unsafe读写未导出字段，仅用于测试数据构造、快照等场景。
+// 默认模式下未导出字段视为不存在（返回ErrFieldNotFound），不会读取或修改未导出的状态
+func AllowUnexported() PathOption {
+	return func(o *pathOptions) {
+		o.unexported = true
+	}
+}
+
+func newPathOptions(opts []PathOption) pathOptions {
+	ret := pathOptions{
+		registry: DefaultConverterRegistry,
+	}
+	for _, opt := range opts {
+		opt(&ret)
+	}
+	return ret
+}
+
+// Set 按照路径设置obj中的值，obj必须是非nil指针，路径语法见SetFieldValueEx
+func Set(obj interface{}, path string, value interface{}, opts ...PathOption) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("Set dest object must be non-nil pointer. ")
+	}
+	w, err := newPathWalker(path, newPathOptions(opts))
+	if err != nil {
+		return err
+	}
+	return w.set(v, reflect.ValueOf(value))
+}
+
+// Get 按照路径获得obj中的值，obj可以是值或指针，路径语法见SetFieldValueEx
+func Get(obj interface{}, path string, opts ...PathOption) (interface{}, error) {
+	w, err := newPathWalker(path, newPathOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	v, err := w.get(reflect.ValueOf(obj))
+	if err != nil {
+		return nil, err
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if !v.CanInterface() {
+		return nil, fmt.Errorf("Path %s cannot be accessed. ", path)
+	}
+	return v.Interface(), nil
+}
+
+// pathWalker 按照路径读写字段
+type pathWalker struct {
+	pathOptions
+
+	path     string
+	segments []pathSegment
+	// 预先解析的片段，与segments一一对应，nil表示运行时解析（如interface之后的片段），见CompilePath
+	steps []*pathStep
+}
+
+func newPathWalker(path string, opts pathOptions) (*pathWalker, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return &pathWalker{
+		pathOptions: opts,
+		path:        path,
+		segments:    segs,
+	}, nil
+}
+
+func (w *pathWalker) pathError(i int, err error) error {
+	return &PathError{Path: w.path, Field: w.segments[i].String(), Index: i, Err: err}
+}
+
+// get 读取路径上的值
+func (w *pathWalker) get(v reflect.Value) (reflect.Value, error) {
+	for i, seg := range w.segments {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, w.pathError(i, ErrNilOnPath)
+			}
+			v = v.Elem()
+		}
+		if w.unexported && v.Kind() == reflect.Struct {
+			v = addressable(v)
+		}
+		next, err := w.child(i, seg, v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v = next
+	}
+	return v, nil
+}
+
+// child 读取片段对应的值
+func (w *pathWalker) child(i int, seg pathSegment, v reflect.Value) (reflect.Value, error) {
+	switch seg.kind {
+	case segmentField:
+		return w.field(i, seg, v, false)
+	case segmentIndex:
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array:
+			idx, err := w.index(i, seg, v)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return v.Index(idx), nil
+		case reflect.Map:
+			key, err := w.mapKey(i, seg, v)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			ev := v.MapIndex(key)
+			if !ev.IsValid() {
+				return reflect.Value{}, w.pathError(i, ErrKeyNotFound)
+			}
+			return ev, nil
+		}
+	}
+	return reflect.Value{}, w.pathError(i, ErrInvalidPath)
+}
+
+// set 设置路径上的值，map元素不可寻址，复制后修改再写回
+func (w *pathWalker) set(v reflect.Value, value reflect.Value) error {
+	return w.setFrom(0, v, value)
+}
+
+func (w *pathWalker) setFrom(i int, v reflect.Value, value reflect.Value) error {
+	if i == len(w.segments) {
+		return setValueE(v, value, w.registry)
+	}
+	seg := w.segments[i]
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if !w.autoCreate || !v.CanSet() {
+				return w.pathError(i, ErrNilOnPath)
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return w.pathError(i, ErrNilOnPath)
+		}
+		ev := v.Elem()
+		if ev.Kind() == reflect.Ptr {
+			return w.setFrom(i, ev, value)
+		}
+		cp := reflect.New(ev.Type()).Elem()
+		cp.Set(ev)
+		if err := w.setFrom(i, cp, value); err != nil {
+			return err
+		}
+		v.Set(cp)
+		return nil
+	}
+
+	switch seg.kind {
+	case segmentAppend:
+		if v.Kind() != reflect.Slice {
+			return w.pathError(i, ErrInvalidPath)
+		}
+		ev := reflect.New(v.Type().Elem()).Elem()
+		if err := w.setFrom(i+1, ev, value); err != nil {
+			return err
+		}
+		v.Set(reflect.Append(v, ev))
+		return nil
+	case segmentIndex:
+		if v.Kind() == reflect.Map {
+			key, err := w.mapKey(i, seg, v)
+			if err != nil {
+				return err
+			}
+			ev := reflect.New(v.Type().Elem()).Elem()
+			if i+1 < len(w.segments) {
+				if old := v.MapIndex(key); old.IsValid() {
+					ev.Set(old)
+				} else if !w.autoCreate {
+					return w.pathError(i, ErrKeyNotFound)
+				}
+			}
+			if err := w.setFrom(i+1, ev, value); err != nil {
+				return err
+			}
+			if v.IsNil() {
+				if !w.autoCreate || !v.CanSet() {
+					return w.pathError(i, ErrNilOnPath)
+				}
+				v.Set(reflect.MakeMap(v.Type()))
+			}
+			v.SetMapIndex(key, ev)
+			return nil
+		}
+		if v.Kind() == reflect.Slice && w.autoCreate {
+			if idx, err := w.position(i, seg); err == nil && idx >= v.Len() && v.CanSet() {
+				grown := reflect.MakeSlice(v.Type(), idx+1, idx+1)
+				reflect.Copy(grown, v)
+				v.Set(grown)
+			}
+		}
+	case segmentField:
+		if v.Kind() == reflect.Struct {
+			next, err := w.field(i, seg, v, w.autoCreate)
+			if err != nil {
+				return err
+			}
+			return w.setFrom(i+1, next, value)
+		}
+	}
+	next, err := w.child(i, seg, v)
+	if err != nil {
+		return err
+	}
+	return w.setFrom(i+1, next, value)
+}
+
+// field 获得结构体字段，路径上的nil嵌入结构体指针在create为true时分配新值，否则返回ErrNilOnPath
+func (w *pathWalker) field(i int, seg pathSegment, v reflect.Value, create bool) (reflect.Value, error) {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, w.pathError(i, ErrFieldNotFound)
+	}
+	index, err := w.fieldIndex(i, seg, v.Type())
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	var fv reflect.Value
+	if w.unexported {
+		fv = fieldByIndexUnexported(v, index, create)
+	} else if create {
+		fv = fieldByIndexForSet(v, index)
+	} else {
+		fv = fieldByIndexForGet(v, index)
+	}
+	if !fv.IsValid() {
+		return reflect.Value{}, w.pathError(i, ErrNilOnPath)
+	}
+	return fv, nil
+}
+
+func (w *pathWalker) fieldIndex(i int, seg pathSegment, t reflect.Type) ([]int, error) {
+	if w.steps != nil && w.steps[i] != nil {
+		return w.steps[i].index, nil
+	}
+	name := seg.name
+	if w.modifier != nil {
+		name = w.modifier(name)
+	}
+	index, ok := lookupFieldIndex(t, name, w.tagNames, w.naming, w.unexported)
+	if !ok {
+		return nil, w.pathError(i, ErrFieldNotFound)
+	}
+	return index, nil
+}
+
+// position 获得片段中的下标，不检查范围
+func (w *pathWalker) position(i int, seg pathSegment) (int, error) {
+	if w.steps != nil && w.steps[i] != nil {
+		return w.steps[i].pos, nil
+	}
+	if seg.quoted {
+		return 0, w.pathError(i, ErrInvalidPath)
+	}
+	idx, err := strconv.Atoi(seg.name)
+	if err != nil {
+		return 0, w.pathError(i, err)
+	}
+	return idx, nil
+}
+
+func (w *pathWalker) index(i int, seg pathSegment, v reflect.Value) (int, error) {
+	idx, err := w.position(i, seg)
+	if err != nil {
+		return 0, err
+	}
+	if idx < 0 || idx >= v.Len() {
+		return 0, w.pathError(i, ErrIndexOutOfRange)
+	}
+	return idx, nil
+}
+
+func (w *pathWalker) mapKey(i int, seg pathSegment, v reflect.Value) (reflect.Value, error) {
+	if w.steps != nil && w.steps[i] != nil {
+		return w.steps[i].key, nil
+	}
+	return w.convertKey(i, seg, v.Type().Key())
+}
+
+func (w *pathWalker) convertKey(i int, seg pathSegment, t reflect.Type) (reflect.Value, error) {
+	key := reflect.New(t).Elem()
+	if err := setValueE(key, reflect.ValueOf(seg.name), w.registry); err != nil {
+		return reflect.Value{}, w.pathError(i, err)
+	}
+	return key, nil
+}
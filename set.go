@@ -15,24 +15,72 @@ import (
 	"time"
 )
 
-func SetValueInterface(dst interface{}, v interface{}) error {
+func SetValueInterface(dst interface{}, v interface{}, registry ...*ConverterRegistry) error {
 	f := reflect.ValueOf(dst)
 	if err := MustPtrValue(f); err != nil {
 		return errors.New("Dest must be Pointer. ")
 	}
 	f = f.Elem()
-	ok := SetValue(f, reflect.ValueOf(v))
-	if !ok {
-		return errors.New("Not assigned. ")
-	}
-	return nil
+	return setValueE(f, reflect.ValueOf(v), selectRegistry(registry))
 }
 
+// SetValue 使用默认转换器注册表赋值
 func SetValue(dst reflect.Value, value reflect.Value) bool {
+	return SetValueE(dst, value) == nil
+}
+
+// SetValueE 使用默认转换器注册表赋值，失败时返回*ConversionError
+func SetValueE(dst reflect.Value, value reflect.Value) error {
+	return setValueE(dst, value, DefaultConverterRegistry)
+}
+
+func setValue(dst reflect.Value, value reflect.Value, registry *ConverterRegistry) bool {
+	return setValueE(dst, value, registry) == nil
+}
+
+func setValueE(dst reflect.Value, value reflect.Value, registry *ConverterRegistry) error {
+	if !dst.IsValid() || !dst.CanSet() {
+		return newConversionError(dst, value, ErrInvalidValue)
+	}
+	dt := dst.Type()
+	// 解引用源接口，nil源值设置为零值
+	for value.IsValid() && value.Kind() == reflect.Interface && !value.IsNil() {
+		value = value.Elem()
+	}
+	if !value.IsValid() || (value.Kind() == reflect.Interface && value.IsNil()) {
+		dst.Set(reflect.Zero(dt))
+		return nil
+	}
+	if registry != nil {
+		ok, err := registry.Convert(dst, value)
+		if err != nil {
+			return newConversionError(dst, value, err)
+		}
+		if ok {
+			return nil
+		}
+	}
+
+	if ok, err := setPointerValue(dst, value, registry); err != nil {
+		return err
+	} else if ok {
+		return nil
+	}
+
+	builtinFirst := preferBuiltin(dt, value)
+	if !builtinFirst {
+		if ok, err := setByUnmarshaler(dst, value); err != nil {
+			return newConversionError(dst, value, err)
+		} else if ok {
+			return nil
+		}
+	}
+
 	hasAssigned := false
+	// 转换失败的原因
+	var cause error
 	vt := value.Type()
 
-	dt := dst.Type()
 	switch dt.Kind() {
 	case reflect.Bool:
 		switch vt.Kind() {
@@ -56,6 +104,7 @@ func SetValue(dst reflect.Value, value reflect.Value) bool {
 			break
 		case reflect.String:
 			b, err := strconv.ParseBool(value.String())
+			cause = err
 			if err == nil {
 				hasAssigned = true
 				dst.SetBool(b)
@@ -66,8 +115,8 @@ func SetValue(dst reflect.Value, value reflect.Value) bool {
 	case reflect.Map:
 		switch vt.Kind() {
 		case reflect.Map:
-			_, err := SetOrCopyMap(dst, value, true)
-			hasAssigned = err == nil
+			_, cause = SetOrCopyMap(dst, value, true, registry)
+			hasAssigned = cause == nil
 		}
 		break
 	case reflect.Slice:
@@ -79,11 +128,22 @@ func SetValue(dst reflect.Value, value reflect.Value) bool {
 			}
 			break
 		case reflect.Slice:
-			_, err := SetOrCopySlice(dst, value, true)
-			hasAssigned = err == nil
+			_, cause = SetOrCopySlice(dst, value, true, registry)
+			hasAssigned = cause == nil
 			break
 		}
 	case reflect.String:
+		// 源类型不能直接赋值时优先使用fmt.Stringer、encoding.TextMarshaler、driver.Valuer
+		if !vt.AssignableTo(dt) {
+			if str, ok, err := formatByInterface(value); ok {
+				cause = err
+				if err == nil {
+					hasAssigned = true
+					dst.SetString(str)
+				}
+				break
+			}
+		}
 		switch vt.Kind() {
 		case reflect.String:
 			hasAssigned = true
@@ -128,8 +188,12 @@ func SetValue(dst reflect.Value, value reflect.Value) bool {
 		//        dst.SetString(fmt.Sprintf("%v", v))
 		//    }
 		default:
-			hasAssigned = true
-			dst.SetString(fmt.Sprintf("%v", value.Interface()))
+			str, err := formatString(value)
+			cause = err
+			if err == nil {
+				hasAssigned = true
+				dst.SetString(str)
+			}
 		}
 		break
 	case reflect.Complex64, reflect.Complex128:
@@ -145,13 +209,14 @@ func SetValue(dst reflect.Value, value reflect.Value) bool {
 					if dst.CanAddr() {
 						err := json.Unmarshal(d, dst.Addr().Interface())
 						if err != nil {
-							return false
+							return newConversionError(dst, value, err)
 						}
+						hasAssigned = true
 					} else {
 						x := reflect.New(dt)
 						err := json.Unmarshal(d, x.Interface())
 						if err != nil {
-							return false
+							return newConversionError(dst, value, err)
 						}
 						hasAssigned = true
 						dst.Set(x.Elem())
@@ -171,6 +236,7 @@ func SetValue(dst reflect.Value, value reflect.Value) bool {
 		case reflect.Slice:
 			if d, ok := value.Interface().([]uint8); ok {
 				intV, err := strconv.ParseInt(string(d), 10, 64)
+				cause = err
 				if err == nil {
 					hasAssigned = true
 					dst.SetInt(intV)
@@ -179,6 +245,7 @@ func SetValue(dst reflect.Value, value reflect.Value) bool {
 			break
 		case reflect.String:
 			b, err := strconv.ParseInt(value.String(), 10, 64)
+			cause = err
 			if err == nil {
 				hasAssigned = true
 				dst.SetInt(b)
@@ -195,6 +262,7 @@ func SetValue(dst reflect.Value, value reflect.Value) bool {
 		case reflect.Slice:
 			if d, ok := value.Interface().([]uint8); ok {
 				floatV, err := strconv.ParseFloat(string(d), 64)
+				cause = err
 				if err == nil {
 					hasAssigned = true
 					dst.SetFloat(floatV)
@@ -203,6 +271,7 @@ func SetValue(dst reflect.Value, value reflect.Value) bool {
 			break
 		case reflect.String:
 			b, err := strconv.ParseFloat(value.String(), 10)
+			cause = err
 			if err == nil {
 				hasAssigned = true
 				dst.SetFloat(b)
@@ -223,6 +292,7 @@ func SetValue(dst reflect.Value, value reflect.Value) bool {
 		case reflect.Slice:
 			if d, ok := value.Interface().([]uint8); ok {
 				uintV, err := strconv.ParseUint(string(d), 10, 64)
+				cause = err
 				if err == nil {
 					hasAssigned = true
 					dst.SetUint(uintV)
@@ -231,6 +301,7 @@ func SetValue(dst reflect.Value, value reflect.Value) bool {
 			break
 		case reflect.String:
 			b, err := strconv.ParseUint(value.String(), 10, 64)
+			cause = err
 			if err == nil {
 				hasAssigned = true
 				dst.SetUint(b)
@@ -253,6 +324,7 @@ func SetValue(dst reflect.Value, value reflect.Value) bool {
 				dst.Set(reflect.ValueOf(t).Convert(fieldType))
 			} else if vt == StringType {
 				t, err := convert2Time([]byte(value.String()), time.Local)
+				cause = err
 				if err == nil {
 					hasAssigned = true
 					dst.Set(reflect.ValueOf(t).Convert(fieldType))
@@ -260,6 +332,7 @@ func SetValue(dst reflect.Value, value reflect.Value) bool {
 			} else {
 				if d, ok := value.Interface().([]byte); ok {
 					t, err := convert2Time(d, time.Local)
+					cause = err
 					if err == nil {
 						hasAssigned = true
 						dst.Set(reflect.ValueOf(t).Convert(fieldType))
@@ -273,6 +346,15 @@ func SetValue(dst reflect.Value, value reflect.Value) bool {
 			} else if vt.ConvertibleTo(dt) {
 				hasAssigned = true
 				dst.Set(value.Convert(dt))
+			} else if vt.Kind() == reflect.Map {
+				// map按Decode的规则解析为结构体
+				d := &decoder{tagNames: []string{StructAliasTag}, registry: registry}
+				nv := reflect.New(dt).Elem()
+				cause = d.decodeStruct("", value, nv)
+				if cause == nil {
+					hasAssigned = true
+					dst.Set(nv)
+				}
 			}
 		}
 		break
@@ -299,12 +381,69 @@ func SetValue(dst reflect.Value, value reflect.Value) bool {
 		}
 		break
 	case reflect.Interface:
-		hasAssigned = true
-		dst.Set(value)
+		if vt.AssignableTo(dt) {
+			hasAssigned = true
+			dst.Set(value)
+		}
 		break
 	}
 
-	return hasAssigned
+	if hasAssigned {
+		return nil
+	}
+	if builtinFirst {
+		if ok, err := setByUnmarshaler(dst, value); err != nil {
+			return newConversionError(dst, value, err)
+		} else if ok {
+			return nil
+		}
+	}
+	if cause == nil {
+		cause = ErrUnsupportedConversion
+	}
+	return newConversionError(dst, value, cause)
+}
+
+// setPointerValue 目标为指针时赋值到指向的值，nil指针分配新值（支持多级指针），源为指针时解引用后赋值，nil源指针设置为零值
+func setPointerValue(dst reflect.Value, value reflect.Value, registry *ConverterRegistry) (bool, error) {
+	dt := dst.Type()
+	vt := value.Type()
+	if vt.AssignableTo(dt) {
+		return false, nil
+	}
+	if dt.Kind() == reflect.Ptr {
+		if vt.ConvertibleTo(dt) {
+			return false, nil
+		}
+		if vt.Kind() == reflect.Ptr && value.IsNil() {
+			dst.Set(reflect.Zero(dt))
+			return true, nil
+		}
+		// 在临时值上赋值，成功后再设置，失败时不修改dst
+		tmp := reflect.New(dt.Elem()).Elem()
+		if dst.IsNil() {
+			if err := setValueE(tmp, value, registry); err != nil {
+				return false, err
+			}
+			dst.Set(tmp.Addr())
+			return true, nil
+		}
+		// 非nil指针保持指向不变，只修改指向的值
+		tmp.Set(dst.Elem())
+		if err := setValueE(tmp, value, registry); err != nil {
+			return false, err
+		}
+		dst.Elem().Set(tmp)
+		return true, nil
+	}
+	if vt.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			dst.Set(reflect.Zero(dt))
+			return true, nil
+		}
+		return true, setValueE(dst, value.Elem(), registry)
+	}
+	return false, nil
 }
 
 const (
@@ -316,10 +455,11 @@ func convert2Time(data []byte, location *time.Location) (time.Time, error) {
 	timeStr := strings.TrimSpace(string(data))
 	var timeRet time.Time
 	var err error
-	if timeStr == zeroTime0 || timeStr == zeroTime1 {
+	if timeStr == "" || timeStr == zeroTime0 || timeStr == zeroTime1 {
 	} else if !strings.ContainsAny(timeStr, "- :") {
 		// time stamp
-		sd, err := strconv.ParseInt(timeStr, 10, 64)
+		var sd int64
+		sd, err = strconv.ParseInt(timeStr, 10, 64)
 		if err == nil {
 			timeRet = time.Unix(sd, 0)
 		}
@@ -335,8 +475,10 @@ func convert2Time(data []byte, location *time.Location) (time.Time, error) {
 		timeRet, err = time.ParseInLocation("2006-01-02 15:04:05", timeStr, location)
 	} else if len(timeStr) == 10 && timeStr[4] == '-' && timeStr[7] == '-' {
 		timeRet, err = time.ParseInLocation("2006-01-02", timeStr, location)
+	} else {
+		err = fmt.Errorf("Time format of %s not support. ", timeStr)
 	}
-	return timeRet, nil
+	return timeRet, err
 }
 
 func MustPtr(bean interface{}) error {
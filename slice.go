@@ -10,21 +10,24 @@ import (
 	"reflect"
 )
 
-func CopySliceInterface(dest, src interface{}) (int, error) {
+func CopySliceInterface(dest, src interface{}, registry ...*ConverterRegistry) (int, error) {
 	t := reflect.TypeOf(dest)
 	v := reflect.ValueOf(dest)
 	if t.Kind() != reflect.Ptr {
 		return 0, errors.New("Dest Type is not a ptr. " + t.String())
 	}
 
-	return CopySlice(v.Elem(), reflect.ValueOf(src))
+	return CopySlice(v.Elem(), reflect.ValueOf(src), registry...)
 }
 
-func CopySlice(dest, src reflect.Value) (int, error) {
-	return SetOrCopySlice(dest, src, false)
+func CopySlice(dest, src reflect.Value, registry ...*ConverterRegistry) (int, error) {
+	return SetOrCopySlice(dest, src, false, registry...)
 }
 
-func SetOrCopySlice(dest, src reflect.Value, set bool) (int, error) {
+// SetOrCopySlice 将src中的元素拷贝到dest中，set为true时替换dest，否则追加到dest中（元素类型相同时覆盖拷贝）。
+// 元素类型不同时逐个转换：注册了转换器时使用SetValue的规则，否则依次尝试直接赋值、类型转换，最后使用SetValue的规则（包括map解析为结构体）转换。
+// 返回成功的元素个数，存在转换失败的元素时返回*CopyError：set为true时dest不变，否则dest包含成功的元素
+func SetOrCopySlice(dest, src reflect.Value, set bool, registry ...*ConverterRegistry) (int, error) {
 	destType := dest.Type()
 	if destType.Kind() != reflect.Slice {
 		return 0, errors.New("Dest Type is not a slice ptr. " + destType.String())
@@ -36,9 +39,13 @@ func SetOrCopySlice(dest, src reflect.Value, set bool) (int, error) {
 	}
 	srcElemType := srcType.Elem()
 
-	if destElemType.Kind() == srcElemType.Kind() {
+	if destElemType == srcElemType || (set && srcType.AssignableTo(destType)) {
 		if set {
-			dest.Set(src)
+			if srcType.AssignableTo(destType) {
+				dest.Set(src)
+			} else {
+				dest.Set(src.Convert(destType))
+			}
 			return dest.Len(), nil
 		} else {
 			destTmp := dest
@@ -52,22 +59,57 @@ func SetOrCopySlice(dest, src reflect.Value, set bool) (int, error) {
 	} else {
 		n := 0
 		destTmp := dest
+		if set {
+			destTmp = reflect.MakeSlice(destType, 0, src.Len())
+		}
+		r := selectRegistry(registry)
+		var failed []*ElementError
 		for i := 0; i < src.Len(); i++ {
-			ov := src.Index(i)
-			ot := ov.Type()
-			// interface
-			if ot.AssignableTo(destElemType) {
-				destTmp = reflect.Append(destTmp, ov)
-				n++
+			ev, err := convertElem(destElemType, src.Index(i), r)
+			if err != nil {
+				failed = append(failed, &ElementError{Index: i, Err: err})
 				continue
 			}
-			if ot.ConvertibleTo(destElemType) {
-				destTmp = reflect.Append(destTmp, ov.Convert(destElemType))
-				n++
-				continue
+			destTmp = reflect.Append(destTmp, ev)
+			n++
+		}
+		if len(failed) > 0 {
+			if !set {
+				dest.Set(destTmp)
 			}
+			return n, &CopyError{Elements: failed}
 		}
 		dest.Set(destTmp)
 		return n, nil
 	}
 }
+
+// convertElem 将元素ov转换为t类型。
+// 注册了转换器时使用SetValue的规则（不使用reflect的类型转换绕过转换器），转换器返回的错误作为元素错误返回
+func convertElem(t reflect.Type, ov reflect.Value, r *ConverterRegistry) (reflect.Value, error) {
+	ot := ov.Type()
+	if r.Lookup(ot, t) == nil {
+		// interface
+		if ot.AssignableTo(t) {
+			return ov, nil
+		}
+		// 整数转换为字符串时Convert按rune转换，使用SetValue的规则格式化
+		if ot.ConvertibleTo(t) && !(t.Kind() == reflect.String && isIntegerKind(ot.Kind())) {
+			return ov.Convert(t), nil
+		}
+	}
+	ev := reflect.New(t).Elem()
+	if err := setValueE(ev, ov, r); err != nil {
+		return reflect.Value{}, err
+	}
+	return ev, nil
+}
+
+func isIntegerKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
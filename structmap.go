@@ -0,0 +1,287 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package reflection
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const (
+	// tag选项：值为空时不输出
+	tagOptionOmitEmpty = "omitempty"
+	// tag选项：将结构体字段展开到父map中，同squash
+	tagOptionInline = "inline"
+	// tag选项：将值格式化为字符串输出
+	tagOptionString = "string"
+)
+
+// NilPointerPolicy Encode处理nil指针的策略
+type NilPointerPolicy int
+
+const (
+	// NilPointerKeep 输出nil
+	NilPointerKeep NilPointerPolicy = iota
+	// NilPointerOmit 不输出该key
+	NilPointerOmit
+	// NilPointerZero 输出指针元素类型的零值
+	NilPointerZero
+)
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// EncodeOption 配置Encode
+type EncodeOption func(e *encoder)
+
+type encoder struct {
+	tagNames   []string
+	nilPointer NilPointerPolicy
+	unexported bool
+	// 当前路径上的指针，用于检测循环引用
+	visiting map[uintptr]bool
+}
+
+// EncodeTagName 指定输出key使用的tag，默认为StructAliasTag
+func EncodeTagName(tagName string) EncodeOption {
+	return func(e *encoder) {
+		e.tagNames = tagNames(tagName)
+	}
+}
+
+// EncodeTagNames 指定输出key使用的tag列表，按顺序使用第一个含有名称的tag，规则同WithTagNames
+func EncodeTagNames(tagNames ...string) EncodeOption {
+	return func(e *encoder) {
+		e.tagNames = tagNames
+	}
+}
+
+// EncodeNilPointer 指定nil指针的处理策略，默认为NilPointerKeep
+func EncodeNilPointer(policy NilPointerPolicy) EncodeOption {
+	return func(e *encoder) {
+		e.nilPointer = policy
+	}
+}
+
+// EncodeUnexported 通过unsafe输出未导出字段（key规则与导出字段一致），仅用于测试数据对比、快照等场景，默认忽略未导出字段
+func EncodeUnexported() EncodeOption {
+	return func(e *encoder) {
+		e.unexported = true
+	}
+}
+
+// Encode 将结构体递归转换为map，是Decode的逆操作：
+// 1、key为tag名称（默认为StructAliasTag），没有tag时为字段名称，tag为‘-’及未导出的字段忽略，字段解析规则与StructInfo一致；
+// 2、嵌套结构体转换为map[string]interface{}，slice、array转换为[]interface{}，map转换为map[string]interface{}；
+// 3、匿名结构体字段（没有tag名称）以及含有inline、squash选项的字段展开到父map中，含有remain选项的map字段同样展开；
+// 4、含有omitempty选项的字段值为空时不输出，含有string选项的字段输出格式化后的字符串；
+// 5、time.Time、[]byte以及实现了encoding.TextMarshaler的类型作为叶子节点直接输出。
+func Encode(obj interface{}, opts ...EncodeOption) (map[string]interface{}, error) {
+	e := &encoder{
+		tagNames: []string{StructAliasTag},
+		visiting: map[uintptr]bool{},
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, errors.New("Encode object must not be nil. ")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Encode object must be struct but get %s. ", v.Kind())
+	}
+	ret := map[string]interface{}{}
+	if err := e.encodeStruct(v, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (e *encoder) encodeStruct(v reflect.Value, ret map[string]interface{}) error {
+	if e.unexported {
+		v = addressable(v)
+	}
+	desc := getStructDescriptor(v.Type(), e.tagNames, nil, e.unexported)
+	// remain字段在其他字段之后合并，其他字段优先
+	var remains []reflect.Value
+	for _, f := range desc.Fields {
+		var fv reflect.Value
+		if e.unexported {
+			fv = fieldByIndexUnexported(v, f.Index, false)
+		} else {
+			fv = fieldByIndexForGet(v, f.Index)
+		}
+		// 路径上存在nil嵌入指针
+		if !fv.IsValid() {
+			continue
+		}
+		if f.Options.Contains(tagOptionRemain) {
+			if fv.Kind() == reflect.Map && fv.CanInterface() {
+				remains = append(remains, fv)
+			}
+			continue
+		}
+		if f.Options.Contains(tagOptionOmitEmpty) && isEmptyValue(fv) {
+			continue
+		}
+		if f.Options.Contains(tagOptionString) {
+			s, err := encodeString(fv)
+			if err != nil {
+				return fmt.Errorf("Encode field %s failed: %w", f.Name, err)
+			}
+			ret[f.Alias] = s
+			continue
+		}
+		ev, omit, err := e.encodeValue(fv)
+		if err != nil {
+			return fmt.Errorf("Encode field %s failed: %w", f.Name, err)
+		}
+		if !omit {
+			ret[f.Alias] = ev
+		}
+	}
+	for _, m := range remains {
+		iter := m.MapRange()
+		for iter.Next() {
+			k, err := encodeString(iter.Key())
+			if err != nil {
+				return err
+			}
+			if _, ok := ret[k]; !ok {
+				ret[k] = iter.Value().Interface()
+			}
+		}
+	}
+	return nil
+}
+
+// encodeValue 转换字段值，返回true表示不输出该值
+func (e *encoder) encodeValue(v reflect.Value) (interface{}, bool, error) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil, false, nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			switch e.nilPointer {
+			case NilPointerOmit:
+				return nil, true, nil
+			case NilPointerZero:
+				return e.encodeValue(reflect.New(v.Type().Elem()).Elem())
+			}
+			return nil, false, nil
+		}
+		// 只有指针实现了encoding.TextMarshaler的叶子类型直接输出指针（如*big.Int），避免复制其内部状态
+		if et := v.Type().Elem(); isEncodeLeaf(et) && !et.ConvertibleTo(TimeType) && !et.Implements(textMarshalerType) {
+			return v.Interface(), false, nil
+		}
+		p := v.Pointer()
+		if e.visiting[p] {
+			return nil, false, fmt.Errorf("Encode cycle detected at %s. ", v.Type())
+		}
+		e.visiting[p] = true
+		defer delete(e.visiting, p)
+		return e.encodeValue(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, false, nil
+		}
+		return e.encodeValue(v.Elem())
+	case reflect.Struct:
+		if isEncodeLeaf(v.Type()) {
+			return v.Interface(), false, nil
+		}
+		m := map[string]interface{}{}
+		if err := e.encodeStruct(v, m); err != nil {
+			return nil, false, err
+		}
+		return m, false, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, false, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface(), false, nil
+		}
+		return e.encodeElems(v)
+	case reflect.Array:
+		return e.encodeElems(v)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, false, nil
+		}
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			k, err := encodeString(iter.Key())
+			if err != nil {
+				return nil, false, err
+			}
+			ev, omit, err := e.encodeValue(iter.Value())
+			if err != nil {
+				return nil, false, err
+			}
+			if !omit {
+				m[k] = ev
+			}
+		}
+		return m, false, nil
+	}
+	return v.Interface(), false, nil
+}
+
+func (e *encoder) encodeElems(v reflect.Value) (interface{}, bool, error) {
+	ret := make([]interface{}, v.Len())
+	for i := range ret {
+		ev, _, err := e.encodeValue(v.Index(i))
+		if err != nil {
+			return nil, false, err
+		}
+		ret[i] = ev
+	}
+	return ret, false, nil
+}
+
+// isEncodeLeaf time.Time以及实现了encoding.TextMarshaler（包括指针接收者，如big.Int）的类型作为叶子节点
+func isEncodeLeaf(t reflect.Type) bool {
+	return t.ConvertibleTo(TimeType) || t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType)
+}
+
+func encodeString(v reflect.Value) (string, error) {
+	s := reflect.New(StringType).Elem()
+	if err := SetValueE(s, v); err != nil {
+		return "", err
+	}
+	return s.String(), nil
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	case reflect.Struct:
+		if v.Type().ConvertibleTo(TimeType) {
+			return v.Convert(TimeType).Interface().(time.Time).IsZero()
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package reflection
+
+import (
+	"reflect"
+	"strings"
+)
+
+const (
+	// tag选项：匿名结构体字段不展开，作为普通字段处理
+	tagOptionNoInline = "noinline"
+)
+
+// TagOptions tag中名称之后以逗号分隔的选项，如`alias:"name,omitempty"`中的omitempty
+type TagOptions string
+
+// ParseTag 将tag值拆分为名称与选项，如"name,omitempty"拆分为"name"与"omitempty"
+func ParseTag(tag string) (string, TagOptions) {
+	if idx := strings.Index(tag, ","); idx != -1 {
+		return tag[:idx], TagOptions(tag[idx+1:])
+	}
+	return tag, ""
+}
+
+// Contains 是否包含选项
+func (o TagOptions) Contains(option string) bool {
+	if len(o) == 0 {
+		return false
+	}
+	s := string(o)
+	for s != "" {
+		var next string
+		i := strings.Index(s, ",")
+		if i >= 0 {
+			s, next = s[:i], s[i+1:]
+		}
+		if strings.TrimSpace(s) == option {
+			return true
+		}
+		s = next
+	}
+	return false
+}
+
+// resolveTag 按照tagNames的顺序解析字段的映射名称：
+// 第一个含有名称的tag生效，名称为‘-’时skip为true；所有tag均没有名称时name为空，由调用者使用字段名称。
+// opts为生效的tag的选项，没有生效的tag时为第一个存在的tag的选项
+func resolveTag(tag reflect.StructTag, tagNames []string) (name string, opts TagOptions, skip bool) {
+	found := false
+	for _, tagName := range tagNames {
+		v, ok := tag.Lookup(tagName)
+		if !ok {
+			continue
+		}
+		// 与encoding/json一致，只有“-”表示忽略，“-,”表示名称为“-”
+		if v == "-" {
+			return "", "", true
+		}
+		n, o := ParseTag(v)
+		if n != "" {
+			return n, o, false
+		}
+		if !found {
+			opts, found = o, true
+		}
+	}
+	return "", opts, false
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/reflection"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type testRow struct {
+	Id       int64   `alias:"id"`
+	Username string  `alias:"username"`
+	Password string  `alias:"password"`
+	Email    string  `alias:"email"`
+	Age      int     `alias:"age"`
+	Score    float64 `alias:"score"`
+	Enabled  bool    `alias:"enabled"`
+	Remark   string
+}
+
+func TestStructCache(t *testing.T) {
+	t.Run("shared", func(t *testing.T) {
+		a, err := reflection.GetStructInfo(&testRow{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := reflection.GetStructInfo(&testRow{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if &a.Fields()[0] != &b.Fields()[0] {
+			t.Fatal("expect cached fields")
+		}
+		// 每次返回新的FieldNameMap，修改不影响其他StructInfo
+		a.FieldNameMap["name"] = "Username"
+		delete(a.FieldNameMap, "age")
+		if _, ok := b.FieldNameMap["name"]; ok || b.FieldNameMap["age"] != "Age" {
+			t.Fatal("expect field map copied ", b.FieldNameMap)
+		}
+		n := a.New().(*reflection.StructInfo)
+		if reflect.ValueOf(a.FieldNameMap).Pointer() != reflect.ValueOf(n.FieldNameMap).Pointer() {
+			t.Fatal("expect New reuse field map")
+		}
+		if !n.SetField("name", reflect.ValueOf("jerry")) || n.GetValue().Interface().(testRow).Username != "jerry" {
+			t.Fatal("expect set added alias")
+		}
+		if !n.SetField("username", reflect.ValueOf("tom")) || n.GetValue().Interface().(testRow).Username != "tom" {
+			t.Fatal("expect set username")
+		}
+
+		c, err := reflection.GetReflectStructInfo(reflect.TypeOf(testRow{}), reflect.Value{}, "json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := c.FieldNameMap["Username"]; !ok {
+			t.Fatal("expect cached by tag ", c.FieldNameMap)
+		}
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		a, _ := reflection.GetStructInfo(&testRow{})
+		reflection.InvalidateStructCache(reflect.TypeOf(&testRow{}))
+		b, _ := reflection.GetStructInfo(&testRow{})
+		if &a.Fields()[0] == &b.Fields()[0] {
+			t.Fatal("expect rebuilt fields")
+		}
+		reflection.ResetStructCache()
+		c, _ := reflection.GetStructInfo(&testRow{})
+		if &b.Fields()[0] == &c.Fields()[0] {
+			t.Fatal("expect rebuilt fields")
+		}
+		if len(c.FieldNameMap) != 8 || c.FieldNameMap["Remark"] != "Remark" {
+			t.Fatal("unexpected field map ", c.FieldNameMap)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		reflection.ResetStructCache()
+		wg := sync.WaitGroup{}
+		for i := 0; i < 16; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v := testRow{}
+				info, err := reflection.GetObjectInfo(&v)
+				if err != nil || !info.SetField("id", reflect.ValueOf(1)) || v.Id != 1 {
+					t.Error("set failed ", err)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func benchmarkBindRow(b *testing.B, reset bool) {
+	b.ReportAllocs()
+	id, name, age := reflect.ValueOf(int64(1)), reflect.ValueOf("tom"), reflect.ValueOf(18)
+	for i := 0; i < b.N; i++ {
+		if reset {
+			reflection.ResetStructCache()
+		}
+		v := testRow{}
+		info, err := reflection.GetObjectInfo(&v)
+		if err != nil {
+			b.Fatal(err)
+		}
+		info.SetField("id", id)
+		info.SetField("username", name)
+		info.SetField("age", age)
+	}
+}
+
+// BenchmarkBindRowCached 每行解析一次结构体（使用缓存）
+func BenchmarkBindRowCached(b *testing.B) {
+	benchmarkBindRow(b, false)
+}
+
+// BenchmarkBindRowUncached 每行清空缓存，等同于未使用缓存时的开销
+func BenchmarkBindRowUncached(b *testing.B) {
+	benchmarkBindRow(b, true)
+}
@@ -0,0 +1,156 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"errors"
+	"github.com/xfali/reflection"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testColor int
+
+const (
+	testRed testColor = iota + 1
+	testGreen
+)
+
+var testColorType = reflect.TypeOf(testColor(0))
+
+func parseTestColor(dst, src reflect.Value) (bool, error) {
+	switch strings.ToLower(src.String()) {
+	case "red":
+		dst.SetInt(int64(testRed))
+	case "green":
+		dst.SetInt(int64(testGreen))
+	default:
+		return false, errors.New("unknown color " + src.String())
+	}
+	return true, nil
+}
+
+func TestConverterRegistry(t *testing.T) {
+	t.Run("type converter", func(t *testing.T) {
+		r := reflection.NewConverterRegistry()
+		r.Register(reflection.StringType, testColorType, parseTestColor)
+
+		var c testColor
+		if !r.SetValue(reflect.ValueOf(&c).Elem(), reflect.ValueOf("Green")) {
+			t.Fatal("expect assigned")
+		}
+		if c != testGreen {
+			t.Fatal("expect green but get ", c)
+		}
+
+		if r.SetValue(reflect.ValueOf(&c).Elem(), reflect.ValueOf("blue")) {
+			t.Fatal("converter error must not be assigned")
+		}
+
+		// fall back to built-in conversion
+		if !r.SetValue(reflect.ValueOf(&c).Elem(), reflect.ValueOf(int64(1))) {
+			t.Fatal("expect assigned")
+		}
+		if c != testRed {
+			t.Fatal("expect red but get ", c)
+		}
+
+		// default registry is not affected
+		if reflection.SetValue(reflect.ValueOf(&c).Elem(), reflect.ValueOf("red")) {
+			t.Fatal("default registry must not convert color")
+		}
+	})
+
+	t.Run("kind converter", func(t *testing.T) {
+		r := reflection.NewConverterRegistry()
+		r.RegisterKind(reflect.Bool, func(dst, src reflect.Value) (bool, error) {
+			if src.Kind() != reflect.String {
+				return false, nil
+			}
+			dst.SetBool(src.String() == "yes")
+			return true, nil
+		})
+		b := false
+		if err := reflection.SetValueInterface(&b, "yes", r); err != nil {
+			t.Fatal(err)
+		}
+		if !b {
+			t.Fatal("expect true")
+		}
+		if err := reflection.SetValueInterface(&b, 0, r); err != nil {
+			t.Fatal(err)
+		}
+		if b {
+			t.Fatal("expect false")
+		}
+	})
+
+	t.Run("clone", func(t *testing.T) {
+		r := reflection.NewConverterRegistry()
+		r.Register(reflection.StringType, testColorType, parseTestColor)
+		c := r.Clone()
+		r.Register(reflection.StringType, testColorType, nil)
+		if r.Lookup(reflection.StringType, testColorType) != nil {
+			t.Fatal("expect removed")
+		}
+		if c.Lookup(reflection.StringType, testColorType) == nil {
+			t.Fatal("expect cloned")
+		}
+	})
+
+	t.Run("field and struct", func(t *testing.T) {
+		type palette struct {
+			Main testColor `alias:"main"`
+		}
+		r := reflection.NewConverterRegistry()
+		r.Register(reflection.StringType, testColorType, parseTestColor)
+
+		p := palette{}
+		err := reflection.SetFieldValueEx(reflect.ValueOf(&p), "Main", reflect.ValueOf("red"), "", nil, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Main != testRed {
+			t.Fatal("expect red but get ", p.Main)
+		}
+
+		info, err := reflection.GetObjectInfo(&p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info.(*reflection.StructInfo).Registry = r
+		if !info.SetField("main", reflect.ValueOf("green")) {
+			t.Fatal("expect set")
+		}
+		if p.Main != testGreen {
+			t.Fatal("expect green but get ", p.Main)
+		}
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		r := reflection.NewConverterRegistry()
+		r.Register(reflection.StringType, testColorType, parseTestColor)
+
+		var s []testColor
+		n, err := reflection.CopySliceInterface(&s, []string{"red", "green"}, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 2 || s[0] != testRed || s[1] != testGreen {
+			t.Fatal("expect [red green] but get ", s)
+		}
+
+		var m map[string]testColor
+		n, err = reflection.CopyMapInterface(&m, map[string]string{"a": "green"}, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 || m["a"] != testGreen {
+			t.Fatal("expect green but get ", m)
+		}
+	})
+}
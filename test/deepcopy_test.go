@@ -0,0 +1,108 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/reflection"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testCopyNode struct {
+	Name   string
+	At     time.Time
+	Next   *testCopyNode
+	Shared *testPathItem
+	Alias  *testPathItem
+	Items  []*testPathItem
+	Labels map[string][]int
+	Any    interface{}
+	Fixed  [2]*testPathItem
+	Custom testCopyCustom
+	state  *testPathItem
+}
+
+type testCopyCustom struct {
+	Value  string
+	Copied bool
+}
+
+func (c testCopyCustom) DeepCopy() testCopyCustom {
+	return testCopyCustom{Value: c.Value, Copied: true}
+}
+
+func TestDeepCopy(t *testing.T) {
+	shared := &testPathItem{Name: "shared"}
+	src := &testCopyNode{
+		Name:   "root",
+		At:     time.Now(),
+		Shared: shared,
+		Alias:  shared,
+		Items:  []*testPathItem{shared, {Name: "b"}},
+		Labels: map[string][]int{"a": {1, 2}},
+		Any:    &testPathItem{Name: "any"},
+		Fixed:  [2]*testPathItem{{Name: "f"}},
+		Custom: testCopyCustom{Value: "c"},
+		state:  &testPathItem{Name: "state"},
+	}
+	src.Next = src
+
+	t.Run("deep", func(t *testing.T) {
+		dst := reflection.DeepCopy(src).(*testCopyNode)
+		if dst == src || dst.Shared == src.Shared || dst.Items[1] == src.Items[1] || dst.Fixed[0] == src.Fixed[0] {
+			t.Fatal("pointers must be copied")
+		}
+		if dst.Name != "root" || !dst.At.Equal(src.At) || dst.Items[1].Name != "b" || dst.Fixed[0].Name != "f" {
+			t.Fatal("unexpected ", dst)
+		}
+		if dst.Next != dst {
+			t.Fatal("cycle must be preserved")
+		}
+		if dst.Shared != dst.Alias || dst.Items[0] != dst.Shared {
+			t.Fatal("shared references must be preserved")
+		}
+		dst.Labels["a"][0] = 100
+		if src.Labels["a"][0] != 1 {
+			t.Fatal("map values must be copied")
+		}
+		if p := dst.Any.(*testPathItem); p == src.Any.(*testPathItem) || p.Name != "any" {
+			t.Fatal("interface values must be copied")
+		}
+		if !dst.Custom.Copied || dst.Custom.Value != "c" {
+			t.Fatal("DeepCopy method must be used ", dst.Custom)
+		}
+		if dst.state != src.state {
+			t.Fatal("unexported fields must be shared by default")
+		}
+	})
+
+	t.Run("unexported", func(t *testing.T) {
+		dst := reflection.DeepCopy(src, reflection.CopyUnexported()).(*testCopyNode)
+		if dst.state == src.state || dst.state.Name != "state" {
+			t.Fatal("unexported fields must be copied ", dst.state)
+		}
+	})
+
+	t.Run("value", func(t *testing.T) {
+		dst := testCopyNode{}
+		if err := reflection.DeepCopyValue(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(*src)); err != nil {
+			t.Fatal(err)
+		}
+		if dst.Next == src || dst.Next.Name != "root" || dst.Shared == shared {
+			t.Fatal("unexpected ", dst)
+		}
+		if err := reflection.DeepCopyValue(reflect.ValueOf(dst), reflect.ValueOf(*src)); err == nil {
+			t.Fatal("expect not settable error")
+		}
+		if err := reflection.DeepCopyValue(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(1)); err == nil {
+			t.Fatal("expect type error")
+		}
+		if reflection.DeepCopy(nil) != nil {
+			t.Fatal("expect nil")
+		}
+	})
+}
@@ -6,8 +6,11 @@
 package test
 
 import (
+	"errors"
 	"fmt"
 	"github.com/xfali/reflection"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -270,3 +273,396 @@ func TestField(t *testing.T) {
 		}
 	})
 }
+
+func TestGetField(t *testing.T) {
+	o := testRootStruct{
+		A: 10,
+		B: testBranchStruct{
+			S: "BS1",
+			B: true,
+		},
+	}
+	t.Run("without tag", func(t *testing.T) {
+		v, err := reflection.GetFieldValue(reflect.ValueOf(o), "B.S")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.String() != "BS1" {
+			t.Fatal("expect BS1 but get ", v)
+		}
+
+		v, err = reflection.GetFieldValue(reflect.ValueOf(&o), "C")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !v.IsNil() {
+			t.Fatal("expect nil but get ", v)
+		}
+
+		_, err = reflection.GetFieldValue(reflect.ValueOf(&o), "C.S")
+		if !errors.Is(err, reflection.ErrNilOnPath) {
+			t.Fatal("expect ErrNilOnPath but get ", err)
+		}
+		var pathErr *reflection.PathError
+		if !errors.As(err, &pathErr) || pathErr.Field != "S" || pathErr.Index != 1 {
+			t.Fatal("unexpected error ", err)
+		}
+
+		_, err = reflection.GetFieldValue(reflect.ValueOf(&o), "B.X")
+		if !errors.Is(err, reflection.ErrFieldNotFound) {
+			t.Fatal("expect ErrFieldNotFound but get ", err)
+		}
+		_, err = reflection.GetFieldValue(reflect.ValueOf(&o), "A.X")
+		if !errors.Is(err, reflection.ErrFieldNotFound) {
+			t.Fatal("expect ErrFieldNotFound but get ", err)
+		}
+		_, err = reflection.GetFieldValue(reflect.ValueOf(1), "A")
+		if err == nil {
+			t.Fatal("expect error")
+		}
+	})
+
+	t.Run("with tag", func(t *testing.T) {
+		v, err := reflection.GetFieldValueByTag(reflect.ValueOf(&o), "b.b", "json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !v.Bool() {
+			t.Fatal("expect true")
+		}
+
+		v, err = reflection.GetFieldValueEx(reflect.ValueOf(&o), "B.S", "json", strings.ToLower)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.String() != "BS1" {
+			t.Fatal("expect BS1 but get ", v)
+		}
+	})
+
+	t.Run("typed", func(t *testing.T) {
+		s, err := reflection.GetFieldString(o, "A")
+		if err != nil || s != "10" {
+			t.Fatal("expect 10 but get ", s, err)
+		}
+		i, err := reflection.GetFieldInt(&o, "A")
+		if err != nil || i != 10 {
+			t.Fatal("expect 10 but get ", i, err)
+		}
+		f, err := reflection.GetFieldFloat(&o, "B.S")
+		if err == nil {
+			t.Fatal("expect error but get ", f)
+		}
+		b, err := reflection.GetFieldBool(&o, "B.B")
+		if err != nil || !b {
+			t.Fatal("expect true but get ", b, err)
+		}
+		u, err := reflection.GetFieldUint(&o, "A")
+		if err != nil || u != 10 {
+			t.Fatal("expect 10 but get ", u, err)
+		}
+		_, err = reflection.GetFieldString(&o, "C.S")
+		if !errors.Is(err, reflection.ErrNilOnPath) {
+			t.Fatal("expect ErrNilOnPath but get ", err)
+		}
+	})
+
+	t.Run("set nil on path", func(t *testing.T) {
+		err := reflection.SetStrcutFieldValue(&o, "C.S", "x")
+		if !errors.Is(err, reflection.ErrNilOnPath) {
+			t.Fatal("expect ErrNilOnPath but get ", err)
+		}
+	})
+}
+
+type testPathItem struct {
+	Name  string
+	Price float64
+}
+
+type testPathStruct struct {
+	Items  []testPathItem
+	Ptrs   []*testPathItem
+	Labels map[string]string
+	Scores map[int]int
+	Named  map[string]testPathItem
+	Matrix [][]int
+	Fixed  [2]int
+	Any    interface{}
+}
+
+func TestFieldPath(t *testing.T) {
+	o := testPathStruct{
+		Items:  []testPathItem{{Name: "a"}, {Name: "b"}},
+		Ptrs:   []*testPathItem{{Name: "p"}},
+		Labels: map[string]string{"env": "dev"},
+		Scores: map[int]int{1: 10},
+		Named:  map[string]testPathItem{"x": {Name: "x"}},
+		Matrix: [][]int{{1, 2, 3}, {4, 5, 6}},
+		Any:    testPathItem{Name: "any"},
+	}
+
+	t.Run("get", func(t *testing.T) {
+		v, err := reflection.GetFieldValue(reflect.ValueOf(o), "Items[1].Name")
+		if err != nil || v.String() != "b" {
+			t.Fatal("expect b but get ", v, err)
+		}
+		v, err = reflection.GetFieldValue(reflect.ValueOf(o), `Labels["env"]`)
+		if err != nil || v.String() != "dev" {
+			t.Fatal("expect dev but get ", v, err)
+		}
+		v, err = reflection.GetFieldValue(reflect.ValueOf(o), "Scores[1]")
+		if err != nil || v.Int() != 10 {
+			t.Fatal("expect 10 but get ", v, err)
+		}
+		v, err = reflection.GetFieldValue(reflect.ValueOf(o), "Matrix[1][2]")
+		if err != nil || v.Int() != 6 {
+			t.Fatal("expect 6 but get ", v, err)
+		}
+		v, err = reflection.GetFieldValue(reflect.ValueOf(o), "Any.Name")
+		if err != nil || v.String() != "any" {
+			t.Fatal("expect any but get ", v, err)
+		}
+		s, err := reflection.GetFieldString(&o, "Ptrs[0].Name")
+		if err != nil || s != "p" {
+			t.Fatal("expect p but get ", s, err)
+		}
+	})
+
+	t.Run("set", func(t *testing.T) {
+		if err := reflection.SetStrcutFieldValue(&o, "Items[0].Price", "1.5"); err != nil {
+			t.Fatal(err)
+		}
+		if o.Items[0].Price != 1.5 {
+			t.Fatal("expect 1.5 but get ", o.Items[0].Price)
+		}
+		if err := reflection.SetStrcutFieldValue(&o, `Labels["region"]`, "cn"); err != nil {
+			t.Fatal(err)
+		}
+		if o.Labels["region"] != "cn" {
+			t.Fatal("expect cn but get ", o.Labels)
+		}
+		if err := reflection.SetStrcutFieldValue(&o, "Scores[2]", "20"); err != nil {
+			t.Fatal(err)
+		}
+		if o.Scores[2] != 20 {
+			t.Fatal("expect 20 but get ", o.Scores)
+		}
+		if err := reflection.SetStrcutFieldValue(&o, "Named[x].Price", 3.0); err != nil {
+			t.Fatal(err)
+		}
+		if o.Named["x"].Price != 3 || o.Named["x"].Name != "x" {
+			t.Fatal("map element not written back ", o.Named)
+		}
+		if err := reflection.SetStrcutFieldValue(&o, "Matrix[0][1]", 9); err != nil {
+			t.Fatal(err)
+		}
+		if o.Matrix[0][1] != 9 {
+			t.Fatal("expect 9 but get ", o.Matrix)
+		}
+		if err := reflection.SetStrcutFieldValue(&o, "Fixed[1]", 7); err != nil {
+			t.Fatal(err)
+		}
+		if o.Fixed[1] != 7 {
+			t.Fatal("expect 7 but get ", o.Fixed)
+		}
+		if err := reflection.SetStrcutFieldValue(&o, "Any.Name", "changed"); err != nil {
+			t.Fatal(err)
+		}
+		if o.Any.(testPathItem).Name != "changed" {
+			t.Fatal("expect changed but get ", o.Any)
+		}
+	})
+
+	t.Run("append", func(t *testing.T) {
+		if err := reflection.SetStrcutFieldValue(&o, "Items[].Name", "c"); err != nil {
+			t.Fatal(err)
+		}
+		if err := reflection.SetStrcutFieldValue(&o, "Matrix[-]", []int{7}); err != nil {
+			t.Fatal(err)
+		}
+		if len(o.Items) != 3 || o.Items[2].Name != "c" {
+			t.Fatal("expect appended item but get ", o.Items)
+		}
+		if len(o.Matrix) != 3 || o.Matrix[2][0] != 7 {
+			t.Fatal("expect appended row but get ", o.Matrix)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var pathErr *reflection.PathError
+		_, err := reflection.GetFieldValue(reflect.ValueOf(o), "Items[5].Name")
+		if !errors.Is(err, reflection.ErrIndexOutOfRange) || !errors.As(err, &pathErr) || pathErr.Field != "[5]" || pathErr.Index != 1 {
+			t.Fatal("expect ErrIndexOutOfRange but get ", err)
+		}
+		err = reflection.SetStrcutFieldValue(&o, "Matrix[0][3]", 1)
+		if !errors.Is(err, reflection.ErrIndexOutOfRange) {
+			t.Fatal("expect ErrIndexOutOfRange but get ", err)
+		}
+		_, err = reflection.GetFieldValue(reflect.ValueOf(o), `Labels["none"]`)
+		if !errors.Is(err, reflection.ErrKeyNotFound) {
+			t.Fatal("expect ErrKeyNotFound but get ", err)
+		}
+		err = reflection.SetStrcutFieldValue(&o, "Named[y].Price", 1)
+		if !errors.Is(err, reflection.ErrKeyNotFound) {
+			t.Fatal("expect ErrKeyNotFound but get ", err)
+		}
+		err = reflection.SetStrcutFieldValue(&o, "Fixed[]", 1)
+		if !errors.Is(err, reflection.ErrInvalidPath) {
+			t.Fatal("expect ErrInvalidPath but get ", err)
+		}
+		_, err = reflection.GetFieldValue(reflect.ValueOf(o), `Items[0`)
+		if !errors.Is(err, reflection.ErrInvalidPath) {
+			t.Fatal("expect ErrInvalidPath but get ", err)
+		}
+		err = reflection.SetStrcutFieldValue(&o, "Scores[x]", 1)
+		if err == nil {
+			t.Fatal("expect key conversion error")
+		}
+	})
+}
+
+type TestAutoLeaf struct {
+	C string
+}
+
+type testAutoBranch struct {
+	B     *TestAutoLeaf
+	Items []TestAutoLeaf
+	Tags  map[string]*TestAutoLeaf
+}
+
+type testAutoRoot struct {
+	*TestAutoLeaf
+	A *testAutoBranch
+	M map[string]int
+}
+
+func TestSetAutoCreate(t *testing.T) {
+	t.Run("without auto create", func(t *testing.T) {
+		o := testAutoRoot{}
+		err := reflection.Set(&o, "A.B.C", "x")
+		if !errors.Is(err, reflection.ErrNilOnPath) {
+			t.Fatal("expect ErrNilOnPath but get ", err)
+		}
+		err = reflection.Set(&o, "C", "x")
+		if !errors.Is(err, reflection.ErrNilOnPath) {
+			t.Fatal("expect ErrNilOnPath but get ", err)
+		}
+		err = reflection.Set(&o, "M[a]", 1)
+		if !errors.Is(err, reflection.ErrNilOnPath) {
+			t.Fatal("expect ErrNilOnPath but get ", err)
+		}
+		if o.A != nil || o.M != nil || o.TestAutoLeaf != nil {
+			t.Fatal("must not be created ", o)
+		}
+		if err := reflection.Set(o, "C", "x"); err == nil {
+			t.Fatal("expect error")
+		}
+	})
+
+	t.Run("auto create", func(t *testing.T) {
+		o := testAutoRoot{}
+		if err := reflection.Set(&o, "A.B.C", "x", reflection.WithAutoCreate()); err != nil {
+			t.Fatal(err)
+		}
+		if o.A.B.C != "x" {
+			t.Fatal("expect x but get ", o.A.B.C)
+		}
+		if err := reflection.Set(&o, "C", "embedded", reflection.WithAutoCreate()); err != nil {
+			t.Fatal(err)
+		}
+		if o.TestAutoLeaf.C != "embedded" {
+			t.Fatal("expect embedded but get ", o.TestAutoLeaf)
+		}
+		if err := reflection.Set(&o, "M[a]", 1, reflection.WithAutoCreate()); err != nil {
+			t.Fatal(err)
+		}
+		if o.M["a"] != 1 {
+			t.Fatal("expect 1 but get ", o.M)
+		}
+		if err := reflection.Set(&o, "A.Items[2].C", "z", reflection.WithAutoCreate()); err != nil {
+			t.Fatal(err)
+		}
+		if len(o.A.Items) != 3 || o.A.Items[2].C != "z" {
+			t.Fatal("expect grown slice but get ", o.A.Items)
+		}
+		if err := reflection.Set(&o, `A.Tags["k"].C`, "v", reflection.WithAutoCreate()); err != nil {
+			t.Fatal(err)
+		}
+		if o.A.Tags["k"].C != "v" {
+			t.Fatal("expect v but get ", o.A.Tags)
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		o := testAutoRoot{A: &testAutoBranch{B: &TestAutoLeaf{C: "x"}}}
+		v, err := reflection.Get(o, "A.B.C")
+		if err != nil || v != "x" {
+			t.Fatal("expect x but get ", v, err)
+		}
+		_, err = reflection.Get(o, "C")
+		if !errors.Is(err, reflection.ErrNilOnPath) {
+			t.Fatal("expect ErrNilOnPath but get ", err)
+		}
+		v, err = reflection.Get(&o, "a.b.c", reflection.WithModifier(strings.ToUpper))
+		if err != nil || v != "x" {
+			t.Fatal("expect x but get ", v, err)
+		}
+	})
+}
+
+type testTagStruct struct {
+	Id      int    `db:"id" json:"identity,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Nick    string
+	Secret  string `db:"-" json:"secret"`
+	Comment string `db:",omitempty" json:"comment"`
+}
+
+func TestFieldTagLookup(t *testing.T) {
+	name, opts := reflection.ParseTag("name,omitempty,string")
+	if name != "name" || !opts.Contains("omitempty") || !opts.Contains("string") || opts.Contains("inline") {
+		t.Fatal("unexpected ", name, opts)
+	}
+
+	o := testTagStruct{}
+	if err := reflection.SetStrcutFieldValueByTag(&o, "name", "tom", "json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reflection.SetStrcutFieldValueByTag(&o, "Nick", "t", "json"); err != nil {
+		t.Fatal(err)
+	}
+	if o.Name != "tom" || o.Nick != "t" {
+		t.Fatal("unexpected ", o)
+	}
+	if err := reflection.SetStrcutFieldValueByTag(&o, "Name", "x", "json"); !errors.Is(err, reflection.ErrFieldNotFound) {
+		t.Fatal("expect ErrFieldNotFound but get ", err)
+	}
+
+	opt := reflection.WithTagNames("db", "json")
+	if err := reflection.Set(&o, "id", 1, opt); err != nil {
+		t.Fatal(err)
+	}
+	if err := reflection.Set(&o, "comment", "c", opt); err != nil {
+		t.Fatal(err)
+	}
+	if o.Id != 1 || o.Comment != "c" {
+		t.Fatal("unexpected ", o)
+	}
+	if err := reflection.Set(&o, "identity", 2, opt); !errors.Is(err, reflection.ErrFieldNotFound) {
+		t.Fatal("expect ErrFieldNotFound but get ", err)
+	}
+	if err := reflection.Set(&o, "secret", "s", opt); !errors.Is(err, reflection.ErrFieldNotFound) {
+		t.Fatal("expect ErrFieldNotFound but get ", err)
+	}
+
+	info, err := reflection.GetReflectStructInfo(reflect.TypeOf(o), reflect.Value{}, "db", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]string{"id": "Id", "name": "Name", "Nick": "Nick", "comment": "Comment"}
+	if !reflect.DeepEqual(info.FieldNameMap, expect) {
+		t.Fatalf("expect %v but get %v", expect, info.FieldNameMap)
+	}
+}
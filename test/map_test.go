@@ -6,6 +6,7 @@
 package test
 
 import (
+	"errors"
 	"github.com/xfali/reflection"
 	"testing"
 )
@@ -83,4 +84,47 @@ func TestCopyMap(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("map string int", func(t *testing.T) {
+		src := map[string]string{"a": "1", "b": "x", "c": "y"}
+		dst := map[string]int{"z": 0}
+		n, err := reflection.CopyMapInterface(&dst, src)
+		if n != 1 || dst["a"] != 1 || len(dst) != 2 {
+			t.Fatal("expect a=1 get ", n, dst)
+		}
+		var copyErr *reflection.CopyError
+		if !errors.As(err, &copyErr) || len(copyErr.Elements) != 2 || copyErr.Elements[0].Key != "b" || copyErr.Elements[1].Key != "c" {
+			t.Fatal("expect failed key b, c but get ", err)
+		}
+		t.Log(err)
+	})
+
+	t.Run("map int string", func(t *testing.T) {
+		var dst map[string]string
+		n, err := reflection.CopyMapInterface(&dst, map[string]int{"a": 65})
+		if err != nil || n != 1 || dst["a"] != "65" {
+			t.Fatal("expect a=65 get ", n, dst, err)
+		}
+	})
+
+	t.Run("map checked registry", func(t *testing.T) {
+		reg := reflection.NewCheckedConverterRegistry(reflection.RoundReject)
+		var dst map[string]uint8
+		n, err := reflection.CopyMapInterface(&dst, map[string]int{"a": -1, "b": 2}, reg)
+		if n != 1 || !errors.Is(err, reflection.ErrNegativeToUnsigned) {
+			t.Fatal("expect negative error but get ", n, dst, err)
+		}
+		if _, ok := dst["a"]; ok || dst["b"] != 2 {
+			t.Fatal("expect b=2 get ", dst)
+		}
+	})
+
+	t.Run("map map struct", func(t *testing.T) {
+		src := map[string]interface{}{"home": map[string]interface{}{"city": "shanghai"}}
+		var dst map[string]testAddress
+		n, err := reflection.CopyMapInterface(&dst, src)
+		if err != nil || n != 1 || dst["home"].City != "shanghai" {
+			t.Fatal("unexpected ", n, dst, err)
+		}
+	})
 }
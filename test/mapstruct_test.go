@@ -0,0 +1,248 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"errors"
+	"github.com/xfali/reflection"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type testBase struct {
+	Id        int64     `alias:"id"`
+	CreatedAt time.Time `alias:"created_at"`
+}
+
+type testAddress struct {
+	City   string `alias:"city"`
+	Street string `alias:"street"`
+}
+
+type testCustomer struct {
+	testBase
+	Name      string                 `alias:"name"`
+	Age       *int                   `alias:"age"`
+	Address   testAddress            `alias:"address"`
+	Backup    *testAddress           `alias:"backup"`
+	Tags      []string               `alias:"tags"`
+	Scores    []int                  `alias:"scores"`
+	History   []testAddress          `alias:"history"`
+	Labels    map[string]int         `alias:"labels"`
+	Ignore    string                 `alias:"-"`
+	Audit     testAudit              `alias:",squash"`
+	Remain    map[string]interface{} `alias:",remain"`
+	unexpored string
+}
+
+type testAudit struct {
+	Operator string `alias:"operator"`
+}
+
+func TestDecode(t *testing.T) {
+	input := map[string]interface{}{
+		"id":         "1001",
+		"created_at": "2022-07-01 19:00:00",
+		"name":       "tom",
+		"age":        18.0,
+		"address": map[string]interface{}{
+			"city":   "shanghai",
+			"street": "nanjing road",
+		},
+		"backup": map[string]interface{}{
+			"city": "beijing",
+		},
+		"tags":   []interface{}{"a", "b"},
+		"scores": []interface{}{"1", 2, int64(3)},
+		"history": []interface{}{
+			map[string]interface{}{"city": "hangzhou"},
+			map[string]interface{}{"city": "suzhou"},
+		},
+		"labels":    map[string]interface{}{"x": "1", "y": 2},
+		"Ignore":    "ignore",
+		"operator":  "admin",
+		"extra":     true,
+		"unexpored": "x",
+	}
+
+	t.Run("decode", func(t *testing.T) {
+		v := testCustomer{}
+		// float64 to int needs checked conversion
+		err := reflection.Decode(input, &v, reflection.DecodeRegistry(reflection.NewCheckedConverterRegistry(reflection.RoundReject)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.Id != 1001 || v.CreatedAt.Format("2006-01-02 15:04:05") != "2022-07-01 19:00:00" {
+			t.Fatal("embedded not decoded ", v.testBase)
+		}
+		if v.Name != "tom" || v.Age == nil || *v.Age != 18 {
+			t.Fatal("unexpected ", v.Name, v.Age)
+		}
+		if v.Address.City != "shanghai" || v.Address.Street != "nanjing road" {
+			t.Fatal("unexpected address ", v.Address)
+		}
+		if v.Backup == nil || v.Backup.City != "beijing" {
+			t.Fatal("unexpected backup ", v.Backup)
+		}
+		if len(v.Tags) != 2 || v.Tags[1] != "b" {
+			t.Fatal("unexpected tags ", v.Tags)
+		}
+		if len(v.Scores) != 3 || v.Scores[0] != 1 || v.Scores[2] != 3 {
+			t.Fatal("unexpected scores ", v.Scores)
+		}
+		if len(v.History) != 2 || v.History[1].City != "suzhou" {
+			t.Fatal("unexpected history ", v.History)
+		}
+		if v.Labels["x"] != 1 || v.Labels["y"] != 2 {
+			t.Fatal("unexpected labels ", v.Labels)
+		}
+		if v.Ignore != "" || v.unexpored != "" {
+			t.Fatal("must be ignored")
+		}
+		if v.Audit.Operator != "admin" {
+			t.Fatal("unexpected audit ", v.Audit)
+		}
+		if len(v.Remain) != 3 || v.Remain["extra"] != true || v.Remain["Ignore"] != "ignore" {
+			t.Fatal("unexpected remain ", v.Remain)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		v := testCustomer{}
+		err := reflection.Decode(map[string]interface{}{
+			"history": []interface{}{
+				map[string]interface{}{"city": []int{1}},
+			},
+		}, &v)
+		if !errors.Is(err, reflection.ErrUnsupportedConversion) {
+			t.Fatal("expect ErrUnsupportedConversion but get ", err)
+		}
+		t.Log(err)
+
+		err = reflection.Decode(map[string]interface{}{"id": "x"}, &v)
+		var numErr *strconv.NumError
+		if !errors.As(err, &numErr) {
+			t.Fatal("expect NumError but get ", err)
+		}
+
+		if reflection.Decode(input, v) == nil {
+			t.Fatal("expect error")
+		}
+	})
+
+	t.Run("case insensitive", func(t *testing.T) {
+		v := testAddress{}
+		err := reflection.Decode(map[string]interface{}{"City": "a", "STREET": "b"}, &v)
+		if err != nil || v.City != "" {
+			t.Fatal("must match exactly ", v, err)
+		}
+		err = reflection.Decode(map[string]interface{}{"City": "a", "STREET": "b"}, &v, reflection.DecodeCaseInsensitive())
+		if err != nil || v.City != "a" || v.Street != "b" {
+			t.Fatal("expect {a b} but get ", v, err)
+		}
+		for i := 0; i < 10; i++ {
+			err = reflection.Decode(map[string]interface{}{"City": "a", "CITY": "b", "city": "c"}, &v, reflection.DecodeCaseInsensitive())
+			if err != nil || v.City != "c" {
+				t.Fatal("expect exact match c but get ", v, err)
+			}
+			err = reflection.Decode(map[string]interface{}{"City": "a", "CITY": "b"}, &v, reflection.DecodeCaseInsensitive())
+			if err != nil || v.City != "b" {
+				t.Fatal("expect CITY b but get ", v, err)
+			}
+		}
+	})
+
+	t.Run("tag name", func(t *testing.T) {
+		v := struct {
+			Name string `json:"nick_name"`
+		}{}
+		err := reflection.Decode(map[string]interface{}{"nick_name": "jerry"}, &v, reflection.DecodeTagName("json"))
+		if err != nil || v.Name != "jerry" {
+			t.Fatal("expect jerry but get ", v, err)
+		}
+	})
+	t.Run("typed remain", func(t *testing.T) {
+		v := struct {
+			Name  string            `alias:"name"`
+			Extra map[string]string `alias:",remain"`
+		}{}
+		err := reflection.Decode(map[string]interface{}{"name": "a", "x": "1", "y": 2}, &v)
+		if err != nil || v.Name != "a" || len(v.Extra) != 2 || v.Extra["x"] != "1" || v.Extra["y"] != "2" {
+			t.Fatal("unexpected ", v, err)
+		}
+	})
+
+	t.Run("embedded pointer", func(t *testing.T) {
+		type Audit struct {
+			Operator string `alias:"operator"`
+		}
+		type Outer struct {
+			*Audit
+			Name string `alias:"name"`
+		}
+		v := Outer{}
+		err := reflection.Decode(map[string]interface{}{"name": "a"}, &v)
+		if err != nil || v.Name != "a" || v.Audit != nil {
+			t.Fatal("expect nil embedded pointer but get ", v.Audit, err)
+		}
+		err = reflection.Decode(map[string]interface{}{"operator": "root"}, &v)
+		if err != nil || v.Audit == nil || v.Operator != "root" {
+			t.Fatal("expect root but get ", v.Audit, err)
+		}
+	})
+	t.Run("shared field resolution", func(t *testing.T) {
+		type A struct {
+			X string
+		}
+		type B struct {
+			X string
+		}
+		type Emb struct {
+			A
+			B
+			Name  string `json:"name" alias:"nick"`
+			Dash  string `alias:"-,"`
+			Inner struct {
+				Y string
+			} `alias:",inline"`
+		}
+		v := Emb{}
+		err := reflection.Decode(map[string]interface{}{"X": "x", "nick": "tom", "-": "dash", "Y": "y"}, &v,
+			reflection.DecodeTagNames("json", "alias"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// X在同一层级重名，存在歧义，不赋值
+		if v.A.X != "" || v.B.X != "" {
+			t.Fatal("ambiguous field must not be set ", v)
+		}
+		if v.Name != "" || v.Dash != "dash" || v.Inner.Y != "y" {
+			t.Fatal("unexpected ", v)
+		}
+
+		err = reflection.Decode(map[string]interface{}{"name": "jerry"}, &v, reflection.DecodeTagNames("json", "alias"))
+		if err != nil || v.Name != "jerry" {
+			t.Fatal("expect jerry but get ", v.Name, err)
+		}
+
+		m, err := reflection.Encode(v, reflection.EncodeTagNames("json", "alias"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := m["X"]; ok || m["name"] != "jerry" || m["-"] != "dash" || m["Y"] != "y" {
+			t.Fatal("unexpected ", m)
+		}
+
+		si, _ := reflection.GetReflectStructInfo(reflect.TypeOf(v), reflect.Value{}, "json", "alias")
+		for k := range m {
+			if _, ok := si.FieldNameMap[k]; !ok {
+				t.Fatal("expect same fields as StructInfo but get ", k)
+			}
+		}
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/reflection"
+	"reflect"
+	"testing"
+)
+
+type testNamingStruct struct {
+	UserID   int64
+	UserName string
+	HomeURL  string
+	Email    string `alias:"mail"`
+}
+
+func TestNamingStrategy(t *testing.T) {
+	cases := []struct {
+		naming reflection.NamingStrategy
+		input  []string
+		expect string
+	}{
+		{reflection.SnakeCase, []string{"UserID", "userId", "user_id", "user-id", "USER_ID"}, "user_id"},
+		{reflection.SnakeCase, []string{"URLPath", "url_path"}, "url_path"},
+		{reflection.KebabCase, []string{"HomeURL", "home_url", "homeUrl"}, "home-url"},
+		{reflection.PascalCase, []string{"user_id", "userId", "UserID"}, "UserID"},
+		{reflection.PascalCase, []string{"http_server", "HTTPServer"}, "HTTPServer"},
+		{reflection.CamelCase, []string{"UserID", "user_id", "userID"}, "userID"},
+		{reflection.CamelCase, []string{"URLPath", "url_path"}, "urlPath"},
+		{reflection.CaseInsensitive, []string{"UserName", "USERNAME"}, "username"},
+	}
+	for _, c := range cases {
+		for _, in := range c.input {
+			out := c.naming(in)
+			if out != c.expect {
+				t.Fatalf("%s: expect %s but get %s", in, c.expect, out)
+			}
+			if c.naming(out) != out {
+				t.Fatalf("%s is not idempotent", out)
+			}
+		}
+	}
+}
+
+func TestNamingStrategyLookup(t *testing.T) {
+	t.Run("struct info", func(t *testing.T) {
+		info, err := reflection.GetReflectStructInfoEx(reflect.TypeOf(testNamingStruct{}), reflect.Value{}, reflection.SnakeCase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := map[string]string{"user_id": "UserID", "user_name": "UserName", "home_url": "HomeURL", "mail": "Email"}
+		if !reflect.DeepEqual(info.FieldNameMap, expect) {
+			t.Fatalf("expect %v but get %v", expect, info.FieldNameMap)
+		}
+
+		o := info.New().(*reflection.StructInfo)
+		if !o.SetField("user_name", reflect.ValueOf("tom")) {
+			t.Fatal("set user_name failed")
+		}
+		if !o.SetField("UserID", reflect.ValueOf(int64(1))) {
+			t.Fatal("set UserID failed")
+		}
+		v := o.GetValue().Interface().(testNamingStruct)
+		if v.UserName != "tom" || v.UserID != 1 {
+			t.Fatal("unexpected ", v)
+		}
+
+		plain, err := reflection.GetReflectStructInfo(reflect.TypeOf(testNamingStruct{}), reflect.Value{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := plain.FieldNameMap["UserName"]; !ok {
+			t.Fatal("default must use field name ", plain.FieldNameMap)
+		}
+	})
+
+	t.Run("path", func(t *testing.T) {
+		o := testNamingStruct{}
+		if err := reflection.Set(&o, "user_name", "tom", reflection.WithNamingStrategy(reflection.SnakeCase)); err != nil {
+			t.Fatal(err)
+		}
+		if err := reflection.Set(&o, "homeUrl", "http://x", reflection.WithNamingStrategy(reflection.SnakeCase)); err != nil {
+			t.Fatal(err)
+		}
+		if err := reflection.Set(&o, "USERID", 1, reflection.WithNamingStrategy(reflection.CaseInsensitive)); err != nil {
+			t.Fatal(err)
+		}
+		if o.UserName != "tom" || o.HomeURL != "http://x" || o.UserID != 1 {
+			t.Fatal("unexpected ", o)
+		}
+		if err := reflection.Set(&o, "user_name", "x"); err == nil {
+			t.Fatal("expect not found without naming strategy")
+		}
+	})
+	t.Run("closure", func(t *testing.T) {
+		prefix := func(p string) reflection.NamingStrategy {
+			return func(name string) string {
+				return p + reflection.SnakeCase(name)
+			}
+		}
+		a, _ := reflection.GetReflectStructInfoEx(reflect.TypeOf(testNamingStruct{}), reflect.Value{}, prefix("a_"))
+		b, _ := reflection.GetReflectStructInfoEx(reflect.TypeOf(testNamingStruct{}), reflect.Value{}, prefix("b_"))
+		if _, ok := a.FieldNameMap["a_user_name"]; !ok {
+			t.Fatal("expect a_user_name but get ", a.FieldNameMap)
+		}
+		if _, ok := b.FieldNameMap["b_user_name"]; !ok {
+			t.Fatal("expect b_user_name but get ", b.FieldNameMap)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"errors"
+	"github.com/xfali/reflection"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type testLevel int
+
+func (l *testLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "debug":
+		*l = 1
+	case "info":
+		*l = 2
+	default:
+		return errors.New("unknown level " + string(text))
+	}
+	return nil
+}
+
+func TestCheckedNumeric(t *testing.T) {
+	r := reflection.NewCheckedConverterRegistry(reflection.RoundTruncate)
+
+	t.Run("overflow int", func(t *testing.T) {
+		var i8 int8
+		err := r.SetValueE(reflect.ValueOf(&i8).Elem(), reflect.ValueOf(200))
+		if !errors.Is(err, reflection.ErrOverflow) {
+			t.Fatal("expect ErrOverflow but get ", err)
+		}
+		err = r.SetValueE(reflect.ValueOf(&i8).Elem(), reflect.ValueOf(uint64(math.MaxUint64)))
+		if !errors.Is(err, reflection.ErrOverflow) {
+			t.Fatal("expect ErrOverflow but get ", err)
+		}
+		err = r.SetValueE(reflect.ValueOf(&i8).Elem(), reflect.ValueOf("-129"))
+		if !errors.Is(err, reflection.ErrOverflow) {
+			t.Fatal("expect ErrOverflow but get ", err)
+		}
+		err = r.SetValueE(reflect.ValueOf(&i8).Elem(), reflect.ValueOf(-128))
+		if err != nil || i8 != -128 {
+			t.Fatal("expect -128 but get ", i8, err)
+		}
+
+		// default registry still wraps
+		if !reflection.SetValue(reflect.ValueOf(&i8).Elem(), reflect.ValueOf(200)) {
+			t.Fatal("expect assigned")
+		}
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		var u uint
+		err := r.SetValueE(reflect.ValueOf(&u).Elem(), reflect.ValueOf(-1))
+		if !errors.Is(err, reflection.ErrNegativeToUnsigned) {
+			t.Fatal("expect ErrNegativeToUnsigned but get ", err)
+		}
+		var u16 uint16
+		err = r.SetValueE(reflect.ValueOf(&u16).Elem(), reflect.ValueOf(uint32(70000)))
+		if !errors.Is(err, reflection.ErrOverflow) {
+			t.Fatal("expect ErrOverflow but get ", err)
+		}
+		err = r.SetValueE(reflect.ValueOf(&u16).Elem(), reflect.ValueOf(65535.9))
+		if err != nil || u16 != 65535 {
+			t.Fatal("expect 65535 but get ", u16, err)
+		}
+	})
+
+	t.Run("float to int", func(t *testing.T) {
+		var i int
+		if err := r.SetValueE(reflect.ValueOf(&i).Elem(), reflect.ValueOf(-2.7)); err != nil || i != -2 {
+			t.Fatal("expect -2 but get ", i, err)
+		}
+
+		round := reflection.NewCheckedConverterRegistry(reflection.RoundNearest)
+		if err := round.SetValueE(reflect.ValueOf(&i).Elem(), reflect.ValueOf(-2.5)); err != nil || i != -3 {
+			t.Fatal("expect -3 but get ", i, err)
+		}
+		if err := round.SetValueE(reflect.ValueOf(&i).Elem(), reflect.ValueOf("2.5")); err != nil || i != 3 {
+			t.Fatal("expect 3 but get ", i, err)
+		}
+
+		reject := reflection.NewCheckedConverterRegistry(reflection.RoundReject)
+		err := reject.SetValueE(reflect.ValueOf(&i).Elem(), reflect.ValueOf(2.5))
+		if !errors.Is(err, reflection.ErrFractional) {
+			t.Fatal("expect ErrFractional but get ", err)
+		}
+		if err := reject.SetValueE(reflect.ValueOf(&i).Elem(), reflect.ValueOf(float32(4))); err != nil || i != 4 {
+			t.Fatal("expect 4 but get ", i, err)
+		}
+
+		err = r.SetValueE(reflect.ValueOf(&i).Elem(), reflect.ValueOf(math.Inf(1)))
+		if !errors.Is(err, reflection.ErrOverflow) {
+			t.Fatal("expect ErrOverflow but get ", err)
+		}
+		err = r.SetValueE(reflect.ValueOf(&i).Elem(), reflect.ValueOf(1e19))
+		if !errors.Is(err, reflection.ErrOverflow) {
+			t.Fatal("expect ErrOverflow but get ", err)
+		}
+	})
+
+	t.Run("int to float", func(t *testing.T) {
+		var f float64
+		if err := r.SetValueE(reflect.ValueOf(&f).Elem(), reflect.ValueOf(3)); err != nil || f != 3 {
+			t.Fatal("expect 3 but get ", f, err)
+		}
+		err := r.SetValueE(reflect.ValueOf(&f).Elem(), reflect.ValueOf(int64(1<<53+1)))
+		if !errors.Is(err, reflection.ErrPrecisionLoss) {
+			t.Fatal("expect ErrPrecisionLoss but get ", err)
+		}
+
+		var f32 float32
+		err = r.SetValueE(reflect.ValueOf(&f32).Elem(), reflect.ValueOf(1e40))
+		if !errors.Is(err, reflection.ErrOverflow) {
+			t.Fatal("expect ErrOverflow but get ", err)
+		}
+		err = r.SetValueE(reflect.ValueOf(&f32).Elem(), reflect.ValueOf(1<<24+1))
+		if !errors.Is(err, reflection.ErrPrecisionLoss) {
+			t.Fatal("expect ErrPrecisionLoss but get ", err)
+		}
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		var i int
+		if err := r.SetValueE(reflect.ValueOf(&i).Elem(), reflect.ValueOf(true)); !errors.Is(err, reflection.ErrUnsupportedConversion) {
+			t.Fatal("expect ErrUnsupportedConversion but get ", err)
+		}
+		if err := r.SetValueE(reflect.ValueOf(&i).Elem(), reflect.ValueOf([]byte("12"))); err != nil || i != 12 {
+			t.Fatal("expect 12 but get ", i, err)
+		}
+	})
+	t.Run("unmarshaler", func(t *testing.T) {
+		var l testLevel
+		if err := r.SetValueE(reflect.ValueOf(&l).Elem(), reflect.ValueOf("info")); err != nil || l != 2 {
+			t.Fatal("expect 2 but get ", l, err)
+		}
+		if err := r.SetValueE(reflect.ValueOf(&l).Elem(), reflect.ValueOf(int64(1))); err != nil || l != 1 {
+			t.Fatal("expect 1 but get ", l, err)
+		}
+	})
+}
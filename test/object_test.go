@@ -17,8 +17,10 @@
 package test
 
 import (
+	"fmt"
 	"github.com/xfali/reflection"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -148,6 +150,126 @@ func TestReflectObjectMap(t *testing.T) {
 	t.Logf("after setField username 321 :%v\n", v)
 }
 
+type testMapKey struct {
+	Group string
+	Id    string
+}
+
+func (k *testMapKey) UnmarshalText(text []byte) error {
+	parts := strings.SplitN(string(text), ":", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid key %s", text)
+	}
+	k.Group, k.Id = parts[0], parts[1]
+	return nil
+}
+
+func TestReflectObjectMapElem(t *testing.T) {
+	var v map[string]TestTable
+	info, err := reflection.GetObjectInfo(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"tom", "jerry"} {
+		elem := info.NewElem()
+		if elem == nil || elem.Kind() != reflection.ObjectStruct {
+			t.Fatal("expect struct elem but get ", elem)
+		}
+		elem.SetField("id", reflect.ValueOf(i))
+		elem.SetField("username", reflect.ValueOf(name))
+		if !info.(*reflection.MapInfo).SetFieldObject(name, elem) {
+			t.Fatal("set field object failed")
+		}
+	}
+	if len(v) != 2 || v["tom"].Id != 0 || v["jerry"].Id != 1 || v["jerry"].Username != "jerry" {
+		t.Fatal("unexpected ", v)
+	}
+
+	n := info.New()
+	if n.NewElem() == nil {
+		t.Fatal("expect elem")
+	}
+	if info.(*reflection.MapInfo).SetFieldObject("x", nil) {
+		t.Fatal("must not set nil")
+	}
+}
+
+func TestReflectObjectMapGeneric(t *testing.T) {
+	t.Run("int64 key struct value", func(t *testing.T) {
+		var v map[int64]TestTable
+		info, err := reflection.GetObjectInfo(&v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.SetField("10", reflect.ValueOf(map[string]interface{}{"username": "tom"})) {
+			t.Fatal("set field failed")
+		}
+		if v[10].Username != "tom" {
+			t.Fatal("expect tom but get ", v)
+		}
+		if info.SetField("x", reflect.ValueOf(TestTable{})) {
+			t.Fatal("key x must not be converted to int64")
+		}
+	})
+
+	t.Run("float value", func(t *testing.T) {
+		v := map[string]float64{}
+		info, err := reflection.GetObjectInfo(&v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.SetField("a", reflect.ValueOf("1.5")) || v["a"] != 1.5 {
+			t.Fatal("expect 1.5 but get ", v)
+		}
+		if info.SetField("b", reflect.ValueOf("x")) {
+			t.Fatal("must not set")
+		}
+		if !info.CanSet(reflect.ValueOf(map[string]float64{})) || info.CanSet(reflect.ValueOf(map[string]int{})) {
+			t.Fatal("unexpected CanSet result")
+		}
+		if !info.SetValue(reflect.ValueOf(map[string]float64{"c": 2})) || v["c"] != 2 {
+			t.Fatal("expect 2 but get ", v)
+		}
+	})
+
+	t.Run("text unmarshaler key", func(t *testing.T) {
+		v := map[testMapKey]int{}
+		info, err := reflection.GetObjectInfo(&v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.SetField("a:1", reflect.ValueOf(1)) || v[testMapKey{Group: "a", Id: "1"}] != 1 {
+			t.Fatal("unexpected ", v)
+		}
+	})
+
+	t.Run("unsupported key", func(t *testing.T) {
+		v := map[TestTable]int{}
+		if _, err := reflection.GetObjectInfo(&v); err == nil {
+			t.Fatal("expect error")
+		}
+	})
+
+	t.Run("recursive", func(t *testing.T) {
+		v := testRecMap{}
+		info, err := reflection.GetObjectInfo(&v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.(*reflection.MapInfo).Elem != nil {
+			t.Fatal("expect recursive elem not resolved")
+		}
+		if !info.SetField("a", reflect.ValueOf(testRecMap{"b": nil})) {
+			t.Fatal("set field failed")
+		}
+		if _, ok := v["a"]["b"]; !ok {
+			t.Fatal("unexpected ", v)
+		}
+	})
+}
+
+type testRecMap map[string]testRecMap
+
 func TestReflectObjectSlice2(t *testing.T) {
 	v := []int{}
 	info, err := reflection.GetObjectInfo(&v)
@@ -225,3 +347,517 @@ func TestReflectObjectSlice(t *testing.T) {
 
 	t.Logf(`after AddValue new elem {Username: "x"} :%v\n`, v)
 }
+
+type testIndexTable struct {
+	Id       int64  `alias:"id"`
+	Username string `alias:"username"`
+	password string `alias:"password"`
+}
+
+func TestReflectObjectStructIndex(t *testing.T) {
+	v := testIndexTable{Id: 1, password: "x"}
+	info, err := reflection.GetObjectInfo(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.SetField("username", reflect.ValueOf("tom")) || v.Username != "tom" {
+		t.Fatal("Expect tom but get ", v.Username)
+	}
+	if info.SetField("nickname", reflect.ValueOf("tom")) {
+		t.Fatal("Expect not found")
+	}
+
+	m := info.(*reflection.StructInfo).MapValue()
+	if m["id"] != int64(1) || m["username"] != "tom" {
+		t.Fatal("unexpected map ", m)
+	}
+	if _, ok := m["password"]; ok {
+		t.Fatal("unexported field must be ignored")
+	}
+}
+
+type TestBaseModel struct {
+	Id        int64     `alias:"id"`
+	CreatedAt time.Time `alias:"created_at"`
+	Remark    string    `alias:"remark"`
+}
+
+type TestAuditModel struct {
+	Operator string `alias:"operator"`
+	Remark   string `alias:"remark"`
+}
+
+type TestOwner struct {
+	Owner string
+}
+
+type TestHolder struct {
+	Owner string
+}
+
+type TestEmbedTable struct {
+	TestBaseModel
+	*TestAuditModel
+	TestOwner
+	TestHolder
+	Holder   TestHolder `alias:",noinline"`
+	Username string     `alias:"username"`
+	Id       string     `alias:"user_id"`
+}
+
+func TestReflectObjectStructEmbedded(t *testing.T) {
+	v := TestEmbedTable{}
+	info, err := reflection.GetObjectInfo(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	si := info.(*reflection.StructInfo)
+	t.Log(si.FieldNameMap)
+
+	m := si.MapValue()
+	if _, ok := m["operator"]; ok {
+		t.Fatal("nil embedded pointer must be skipped on read")
+	}
+
+	if !info.SetField("created_at", reflect.ValueOf("2022-07-01 19:00:00")) {
+		t.Fatal("expect set promoted field")
+	}
+	if v.CreatedAt.Format("2006-01-02 15:04:05") != "2022-07-01 19:00:00" {
+		t.Fatal("expect 2022-07-01 19:00:00 but get ", v.CreatedAt)
+	}
+	if !info.SetField("id", reflect.ValueOf(1)) || v.TestBaseModel.Id != 1 {
+		t.Fatal("expect set promoted id")
+	}
+	if !info.SetField("user_id", reflect.ValueOf(2)) || v.Id != "2" {
+		t.Fatal("expect set outer id")
+	}
+
+	if !info.SetField("operator", reflect.ValueOf("admin")) {
+		t.Fatal("expect set through nil embedded pointer")
+	}
+	if v.TestAuditModel == nil || v.Operator != "admin" {
+		t.Fatal("expect allocated embedded pointer")
+	}
+
+	// remark is ambiguous at the same depth
+	if info.SetField("remark", reflect.ValueOf("x")) {
+		t.Fatal("ambiguous field must not be set")
+	}
+	// Owner is ambiguous, TestOwner and TestHolder are flattened
+	if info.SetField("Owner", reflect.ValueOf("x")) || info.SetField("TestOwner", reflect.ValueOf(TestOwner{})) {
+		t.Fatal("ambiguous field must not be set")
+	}
+	if !info.SetField("Holder", reflect.ValueOf(TestHolder{Owner: "tom"})) || v.Holder.Owner != "tom" {
+		t.Fatal("expect set noinline field")
+	}
+
+	m = si.MapValue()
+	if m["operator"] != "admin" || m["id"] != int64(1) || m["user_id"] != "2" {
+		t.Fatal("unexpected map ", m)
+	}
+
+	// 嵌入的time.Time作为叶子字段，不展开
+	lv := struct {
+		time.Time
+		ID int
+	}{}
+	li, err := reflection.GetObjectInfo(&lv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if li.(*reflection.StructInfo).FieldNameMap["Time"] != "Time" {
+		t.Fatal("expect embedded time field but get ", li.(*reflection.StructInfo).FieldNameMap)
+	}
+	if !li.SetField("Time", reflect.ValueOf("2022-07-01 19:00:00")) || lv.Year() != 2022 {
+		t.Fatal("expect 2022 but get ", lv.Time)
+	}
+}
+
+func TestReflectObjectSlicePointer(t *testing.T) {
+	v := []*TestTable{}
+	info, err := reflection.GetObjectInfo(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b"} {
+		ev := info.NewElem()
+		if ev.Kind() != reflection.ObjectPointer {
+			t.Fatal("Expect pointer object but get ", ev.Kind())
+		}
+		if !ev.SetField("username", reflect.ValueOf(name)) {
+			t.Fatal("set field failed")
+		}
+		if !info.AddValue(ev.GetValue()) {
+			t.Fatal("add value failed")
+		}
+	}
+	if len(v) != 2 || v[0].Username != "a" || v[1].Username != "b" {
+		t.Fatal("Expect [a b] but get ", v)
+	}
+	if v[0] == v[1] {
+		t.Fatal("Expect different pointers")
+	}
+	if info.AddValue(reflect.ValueOf(TestTable{Username: "c"})) || len(v) != 2 {
+		t.Fatal("Expect non pointer value rejected")
+	}
+}
+
+func TestReflectObjectArray(t *testing.T) {
+	v := [2]TestTable{}
+	info, err := reflection.GetObjectInfo(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Kind() != reflection.ObjectArray || !info.CanAddValue() || info.CanSetField() {
+		t.Fatal("Expect array object")
+	}
+	for _, name := range []string{"a", "b"} {
+		ev := info.NewElem()
+		ev.SetField("username", reflect.ValueOf(name))
+		if !info.AddValue(ev.GetValue()) {
+			t.Fatal("add value failed")
+		}
+	}
+	if info.AddValue(reflect.ValueOf(TestTable{Username: "c"})) {
+		t.Fatal("Expect add value to full array fail")
+	}
+	if v[0].Username != "a" || v[1].Username != "b" {
+		t.Fatal("Expect [a b] but get ", v)
+	}
+
+	info.ResetValue(reflect.ValueOf(&v).Elem())
+	if !info.AddValue(reflect.ValueOf(TestTable{Username: "c"})) || v[0].Username != "c" {
+		t.Fatal("Expect c but get ", v[0].Username)
+	}
+
+	iv := [2]int{}
+	ii, err := reflection.GetObjectInfo(&iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ii.AddValue(reflect.ValueOf("x")) {
+		t.Fatal("Expect add value x fail")
+	}
+	if !ii.AddValue(reflect.ValueOf("1")) || !ii.AddValue(reflect.ValueOf(int64(2))) || iv != [2]int{1, 2} {
+		t.Fatal("Expect [1 2] but get ", iv)
+	}
+}
+
+type testRecSlice []*testRecSlice
+
+type testRecArray [1]*testRecArray
+
+type testRecNode struct {
+	Name     string         `alias:"name"`
+	Children []*testRecNode `alias:"children"`
+}
+
+func TestReflectObjectRecursive(t *testing.T) {
+	v := testRecSlice{}
+	if _, err := reflection.GetObjectInfo(&v); err == nil {
+		t.Fatal("expect recursive slice error")
+	}
+	a := testRecArray{}
+	if _, err := reflection.GetObjectInfo(&a); err == nil {
+		t.Fatal("expect recursive array error")
+	}
+	if _, err := reflection.GetReflectPointerInfo(reflect.TypeOf(&v), reflect.ValueOf(&v)); err == nil {
+		t.Fatal("expect recursive pointer error")
+	}
+
+	// 结构体字段在使用时解析，通过结构体递归的类型可以解析
+	n := testRecNode{}
+	info, err := reflection.GetObjectInfo(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := info.(*reflection.StructInfo).Fields()
+	children := fields[1].Object()
+	if children == nil || children.Kind() != reflection.ObjectSlice {
+		t.Fatal("expect slice field object but get ", children)
+	}
+	elem := children.NewElem()
+	if elem == nil || !elem.SetField("name", reflect.ValueOf("child")) {
+		t.Fatal("set child name failed")
+	}
+	if !info.SetField("children", reflect.ValueOf([]*testRecNode{elem.GetValue().Interface().(*testRecNode)})) {
+		t.Fatal("set children failed")
+	}
+	if len(n.Children) != 1 || n.Children[0].Name != "child" {
+		t.Fatal("unexpected ", n)
+	}
+}
+
+type testPointerHolder struct {
+	Table *TestTable  `alias:"table"`
+	Value interface{} `alias:"value"`
+}
+
+func TestReflectObjectPointer(t *testing.T) {
+	v := testPointerHolder{}
+	info, err := reflection.GetObjectInfo(&v.Table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Kind() != reflection.ObjectPointer || !info.CanSetField() {
+		t.Fatal("Expect pointer object")
+	}
+	if !info.SetField("username", reflect.ValueOf("a")) {
+		t.Fatal("set field failed")
+	}
+	if v.Table == nil || v.Table.Username != "a" {
+		t.Fatal("Expect a but get ", v.Table)
+	}
+	p := v.Table
+	info.SetField("password", reflect.ValueOf("b"))
+	if v.Table != p || v.Table.Password != "b" {
+		t.Fatal("Expect same pointer but get ", v.Table)
+	}
+
+	info.SetValue(reflect.ValueOf(&TestTable{Username: "c"}))
+	if v.Table == p || v.Table.Username != "c" {
+		t.Fatal("Expect c but get ", v.Table)
+	}
+	info.SetField("password", reflect.ValueOf("d"))
+	if v.Table.Password != "d" || p.Password != "b" {
+		t.Fatal("Expect d but get ", v.Table)
+	}
+}
+
+func TestReflectObjectInterface(t *testing.T) {
+	v := testPointerHolder{}
+	info, err := reflection.GetObjectInfo(&v.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Kind() != reflection.ObjectInterface || info.CanSetField() || info.NewElem() != nil {
+		t.Fatal("Expect empty interface object")
+	}
+
+	t.Run("value", func(t *testing.T) {
+		info.SetValue(reflect.ValueOf(TestTable{}))
+		if !info.CanSetField() || !info.SetField("username", reflect.ValueOf("a")) {
+			t.Fatal("set field failed")
+		}
+		if v.Value.(TestTable).Username != "a" {
+			t.Fatal("Expect a but get ", v.Value)
+		}
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		p := &TestTable{}
+		info.SetValue(reflect.ValueOf(p))
+		info.SetField("username", reflect.ValueOf("b"))
+		if v.Value != p || p.Username != "b" {
+			t.Fatal("Expect b but get ", v.Value)
+		}
+	})
+
+	t.Run("repoint", func(t *testing.T) {
+		a1, a2 := &TestTable{}, &TestTable{}
+		info.SetValue(reflect.ValueOf(a1))
+		info.SetField("id", reflect.ValueOf(int64(1)))
+		v.Value = a2
+		info.SetField("id", reflect.ValueOf(int64(2)))
+		if a1.Id != 1 || a2.Id != 2 {
+			t.Fatal("Expect 1 2 but get ", a1.Id, a2.Id)
+		}
+
+		v.Value = TestTable{Username: "x"}
+		info.SetField("id", reflect.ValueOf(int64(3)))
+		if tt := v.Value.(TestTable); tt.Username != "x" || tt.Id != 3 {
+			t.Fatal("Expect {3 x} but get ", v.Value)
+		}
+		v.Value = TestTable{Username: "y"}
+		info.SetField("id", reflect.ValueOf(int64(4)))
+		if tt := v.Value.(TestTable); tt.Username != "y" || tt.Id != 4 {
+			t.Fatal("Expect {4 y} but get ", v.Value)
+		}
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		info.SetValue(reflect.ValueOf([]int{}))
+		if !info.CanAddValue() || !info.AddValue(reflect.ValueOf(1)) {
+			t.Fatal("add value failed")
+		}
+		if s := v.Value.([]int); len(s) != 1 || s[0] != 1 {
+			t.Fatal("Expect [1] but get ", v.Value)
+		}
+	})
+}
+
+type testDecimal struct {
+	unscaled int64
+	scale    int
+}
+
+type testMoney struct {
+	Amount   int64
+	Currency string
+}
+
+type testMoneyInfo struct {
+	reflection.Object
+	kind int
+}
+
+func (info *testMoneyInfo) Kind() int {
+	return info.kind
+}
+
+func (info *testMoneyInfo) New() reflection.Object {
+	return &testMoneyInfo{Object: info.Object.New(), kind: info.kind}
+}
+
+func testMoneyFactory(kind int) reflection.ObjectFactory {
+	return func(rt reflect.Type, rv reflect.Value) (reflection.Object, error) {
+		o, err := reflection.GetReflectSimpleTypeInfo(rt, rv)
+		if err != nil {
+			return nil, err
+		}
+		return &testMoneyInfo{Object: o, kind: kind}, nil
+	}
+}
+
+func TestReflectObjectCustom(t *testing.T) {
+	decimalType := reflect.TypeOf(testDecimal{})
+	moneyType := reflect.TypeOf(testMoney{})
+
+	t.Run("simple type", func(t *testing.T) {
+		if reflection.IsSimpleType(decimalType) {
+			t.Fatal("Expect not simple type before register")
+		}
+		reflection.RegisterSimpleType(decimalType)
+		if !reflection.IsSimpleType(decimalType) {
+			t.Fatal("Expect simple type")
+		}
+		v := testDecimal{}
+		info, err := reflection.GetObjectInfo(&v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Kind() != reflection.ObjectSimpletype {
+			t.Fatal("Expect simple type object but get ", info.Kind())
+		}
+		info.SetValue(reflect.ValueOf(testDecimal{unscaled: 314, scale: 2}))
+		if v.unscaled != 314 {
+			t.Fatal("Expect 314 but get ", v.unscaled)
+		}
+	})
+
+	t.Run("factory", func(t *testing.T) {
+		isMoney := func(rt reflect.Type) bool {
+			return rt == moneyType
+		}
+		reflection.RegisterObjectFactory(isMoney, testMoneyFactory(reflection.ObjectCustom+1))
+		reflection.RegisterObjectFactory(isMoney, testMoneyFactory(reflection.ObjectCustom+2), -1)
+
+		v := testMoney{}
+		info, err := reflection.GetObjectInfo(&v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Kind() != reflection.ObjectCustom+1 {
+			t.Fatal("Expect higher priority factory but get ", info.Kind())
+		}
+
+		reflection.RegisterObjectFactory(isMoney, testMoneyFactory(reflection.ObjectCustom+3))
+		info, _ = reflection.GetObjectInfo(&v)
+		if info.Kind() != reflection.ObjectCustom+3 {
+			t.Fatal("Expect later registered factory but get ", info.Kind())
+		}
+
+		reflection.RegisterTypeObjectFactory(moneyType, testMoneyFactory(reflection.ObjectCustom+4))
+		info, _ = reflection.GetObjectInfo(&v)
+		if info.Kind() != reflection.ObjectCustom+4 {
+			t.Fatal("Expect type factory but get ", info.Kind())
+		}
+		reflection.RegisterTypeObjectFactory(moneyType, nil)
+
+		// 作为slice元素时同样使用自定义Object
+		s := []testMoney{}
+		info, err = reflection.GetObjectInfo(&s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k := info.NewElem().Kind(); k != reflection.ObjectCustom+3 {
+			t.Fatal("Expect custom elem but get ", k)
+		}
+		info.AddValue(reflect.ValueOf(testMoney{Amount: 1}))
+		if len(s) != 1 || s[0].Amount != 1 {
+			t.Fatal("Expect [{1}] but get ", s)
+		}
+	})
+}
+
+type testOrderItem struct {
+	Name  string `alias:"name"`
+	Count int    `alias:"count"`
+}
+
+type testOrder struct {
+	TestBaseModel
+	Items    []*testOrderItem `alias:"items,omitempty"`
+	Children []*testOrder     `alias:"children"`
+}
+
+func TestReflectObjectStructFields(t *testing.T) {
+	v := testOrder{}
+	info, err := reflection.GetObjectInfo(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := info.(*reflection.StructInfo).Fields()
+	var names []string
+	for _, f := range fields {
+		names = append(names, f.Alias)
+	}
+	if strings.Join(names, ",") != "id,created_at,remark,items,children" {
+		t.Fatal("Expect promoted fields in order but get ", names)
+	}
+
+	items := fields[3]
+	if items.Name != "Items" || !items.Options.Contains("omitempty") ||
+		items.Type != reflect.TypeOf([]*testOrderItem{}) || fmt.Sprint(items.Index) != "[1]" {
+		t.Fatal("unexpected field info ", items)
+	}
+	if fmt.Sprint(fields[1].Index) != "[0 1]" {
+		t.Fatal("Expect [0 1] but get ", fields[1].Index)
+	}
+
+	// StructInfo → SliceInfo → PointerInfo → StructInfo
+	obj := items.Object()
+	if obj == nil || obj.Kind() != reflection.ObjectSlice {
+		t.Fatal("Expect slice object")
+	}
+	if o := items.Object(); o == obj || o.Kind() != reflection.ObjectSlice {
+		t.Fatal("Expect new object for each call")
+	}
+	// 字段信息按类型缓存，New生成的StructInfo共享
+	if nf := info.New().(*reflection.StructInfo).Fields(); &nf[0] != &fields[0] {
+		t.Fatal("Expect fields shared by type")
+	}
+	elem := obj.(*reflection.SliceInfo).Elem.(*reflection.PointerInfo).Elem.(*reflection.StructInfo)
+	var elemNames []string
+	for _, f := range elem.Fields() {
+		elemNames = append(elemNames, f.Alias)
+	}
+	if strings.Join(elemNames, ",") != "name,count" {
+		t.Fatal("Expect name,count but get ", elemNames)
+	}
+
+	// 关联到字段值后填充
+	obj.ResetValue(reflect.ValueOf(&v).Elem().FieldByIndex(items.Index))
+	e := obj.NewElem()
+	e.SetField("name", reflect.ValueOf("apple"))
+	obj.AddValue(e.GetValue())
+	if len(v.Items) != 1 || v.Items[0].Name != "apple" {
+		t.Fatal("Expect apple but get ", v.Items)
+	}
+
+	// 自引用类型按需展开
+	children := fields[4].Object().(*reflection.SliceInfo).Elem.(*reflection.PointerInfo).Elem.(*reflection.StructInfo)
+	if len(children.Fields()) != len(fields) {
+		t.Fatal("Expect same fields but get ", children.Fields())
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"errors"
+	"github.com/xfali/reflection"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestCompilePath(t *testing.T) {
+	t.Run("get and set", func(t *testing.T) {
+		a, err := reflection.CompilePath(reflect.TypeOf(testPathStruct{}), "Items[1].Price")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Type() != reflect.TypeOf(float64(0)) {
+			t.Fatal("expect float64 but get ", a.Type())
+		}
+		o := testPathStruct{Items: []testPathItem{{Name: "a"}, {Name: "b"}}}
+		if err := a.Set(&o, "2.5"); err != nil {
+			t.Fatal(err)
+		}
+		v, err := a.Get(o)
+		if err != nil || v != 2.5 {
+			t.Fatal("expect 2.5 but get ", v, err)
+		}
+
+		_, err = a.Get(testPathStruct{})
+		if !errors.Is(err, reflection.ErrIndexOutOfRange) {
+			t.Fatal("expect ErrIndexOutOfRange but get ", err)
+		}
+		if err := a.Set(o, 1.0); err == nil {
+			t.Fatal("expect error")
+		}
+		if _, err := a.Get(testRootStruct{}); err == nil {
+			t.Fatal("expect type error")
+		}
+	})
+
+	t.Run("map and interface", func(t *testing.T) {
+		a, err := reflection.CompilePath(reflect.TypeOf(&testPathStruct{}), "Scores[3]", reflection.WithAutoCreate())
+		if err != nil {
+			t.Fatal(err)
+		}
+		o := testPathStruct{}
+		if err := a.Set(&o, "30"); err != nil {
+			t.Fatal(err)
+		}
+		if o.Scores[3] != 30 {
+			t.Fatal("expect 30 but get ", o.Scores)
+		}
+
+		a, err = reflection.CompilePath(reflect.TypeOf(testPathStruct{}), "Any.Name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Type().Kind() != reflect.Interface {
+			t.Fatal("expect interface but get ", a.Type())
+		}
+		o.Any = &testPathItem{Name: "x"}
+		v, err := a.Get(&o)
+		if err != nil || v != "x" {
+			t.Fatal("expect x but get ", v, err)
+		}
+	})
+
+	t.Run("compile error", func(t *testing.T) {
+		_, err := reflection.CompilePath(reflect.TypeOf(testPathStruct{}), "Items[0].X")
+		if !errors.Is(err, reflection.ErrFieldNotFound) {
+			t.Fatal("expect ErrFieldNotFound but get ", err)
+		}
+		_, err = reflection.CompilePath(reflect.TypeOf(testPathStruct{}), "Fixed[2]")
+		if !errors.Is(err, reflection.ErrIndexOutOfRange) {
+			t.Fatal("expect ErrIndexOutOfRange but get ", err)
+		}
+		_, err = reflection.CompilePath(reflect.TypeOf(testPathStruct{}), "Scores[x]")
+		if err == nil {
+			t.Fatal("expect key error")
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		a, err := reflection.CompilePath(reflect.TypeOf(testPathStruct{}), "Labels[env]", reflection.WithAutoCreate())
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				o := testPathStruct{}
+				if err := a.Set(&o, i); err != nil {
+					t.Error(err)
+					return
+				}
+				if v, err := a.Get(&o); err != nil || v != strconv.Itoa(i) {
+					t.Error("unexpected ", v, err)
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+}
+
+func BenchmarkAccessorSet(b *testing.B) {
+	a, err := reflection.CompilePath(reflect.TypeOf(testPathStruct{}), "Items[0].Price")
+	if err != nil {
+		b.Fatal(err)
+	}
+	o := testPathStruct{Items: []testPathItem{{}}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := a.Set(&o, 1.5); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPathSet(b *testing.B) {
+	o := testPathStruct{Items: []testPathItem{{}}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := reflection.Set(&o, "Items[0].Price", 1.5); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/xfali/reflection"
+	"math/big"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testId struct {
+	prefix string
+	seq    int64
+}
+
+func (id *testId) UnmarshalText(text []byte) error {
+	ss := strings.SplitN(string(text), "-", 2)
+	if len(ss) != 2 {
+		return errors.New("invalid id " + string(text))
+	}
+	seq, err := strconv.ParseInt(ss[1], 10, 64)
+	if err != nil {
+		return err
+	}
+	id.prefix, id.seq = ss[0], seq
+	return nil
+}
+
+func (id testId) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%s-%d", id.prefix, id.seq)), nil
+}
+
+type testPoint struct {
+	X, Y int
+}
+
+func (p testPoint) String() string {
+	return fmt.Sprintf("(%d,%d)", p.X, p.Y)
+}
+
+type testShade int
+
+func (c testShade) String() string {
+	return [...]string{"red", "green", "blue", "black"}[c]
+}
+
+type testName string
+
+func (n testName) String() string {
+	return "name:" + string(n)
+}
+
+func TestSetValueE(t *testing.T) {
+	t.Run("parse error", func(t *testing.T) {
+		i := 0
+		err := reflection.SetValueE(reflect.ValueOf(&i).Elem(), reflect.ValueOf("abc"))
+		if err == nil {
+			t.Fatal("expect error")
+		}
+		var convErr *reflection.ConversionError
+		if !errors.As(err, &convErr) {
+			t.Fatal("expect ConversionError but get ", err)
+		}
+		if convErr.SrcType != reflection.StringType || convErr.DstType != reflection.IntType || convErr.Value != "abc" {
+			t.Fatal("unexpected error content ", convErr)
+		}
+		var numErr *strconv.NumError
+		if !errors.As(err, &numErr) {
+			t.Fatal("expect strconv.NumError but get ", err)
+		}
+		t.Log(err)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		b := false
+		err := reflection.SetValueE(reflect.ValueOf(&b).Elem(), reflect.ValueOf(1.5))
+		if !errors.Is(err, reflection.ErrUnsupportedConversion) {
+			t.Fatal("expect ErrUnsupportedConversion but get ", err)
+		}
+		if reflection.SetValue(reflect.ValueOf(&b).Elem(), reflect.ValueOf(1.5)) {
+			t.Fatal("expect not assigned")
+		}
+
+		err = reflection.SetValueInterface(&b, 1.5)
+		if !errors.Is(err, reflection.ErrUnsupportedConversion) {
+			t.Fatal("expect ErrUnsupportedConversion but get ", err)
+		}
+	})
+
+	t.Run("time error", func(t *testing.T) {
+		v := time.Time{}
+		err := reflection.SetValueE(reflect.ValueOf(&v).Elem(), reflect.ValueOf("2022-13-01 19:00:00"))
+		var timeErr *time.ParseError
+		if !errors.As(err, &timeErr) {
+			t.Fatal("expect time.ParseError but get ", err)
+		}
+
+		err = reflection.SetValueE(reflect.ValueOf(&v).Elem(), reflect.ValueOf("2022-07-01"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.Format("2006-01-02") != "2022-07-01" {
+			t.Fatal("expect 2022-07-01 but get ", v)
+		}
+	})
+
+	t.Run("converter error", func(t *testing.T) {
+		cause := errors.New("test")
+		r := reflection.NewConverterRegistry()
+		r.RegisterKind(reflect.String, func(dst, src reflect.Value) (bool, error) {
+			return false, cause
+		})
+		s := ""
+		err := r.SetValueE(reflect.ValueOf(&s).Elem(), reflect.ValueOf(1))
+		if !errors.Is(err, cause) {
+			t.Fatal("expect cause but get ", err)
+		}
+	})
+
+	t.Run("field", func(t *testing.T) {
+		o := testRootStruct{}
+		err := reflection.SetStrcutFieldValue(&o, "A", "x")
+		var numErr *strconv.NumError
+		if !errors.As(err, &numErr) {
+			t.Fatal("expect strconv.NumError but get ", err)
+		}
+	})
+}
+
+func TestSetValueMarshaler(t *testing.T) {
+	t.Run("text unmarshaler", func(t *testing.T) {
+		var ip net.IP
+		if err := reflection.SetValueInterface(&ip, "192.168.0.1"); err != nil {
+			t.Fatal(err)
+		}
+		if !ip.Equal(net.IPv4(192, 168, 0, 1)) {
+			t.Fatal("expect 192.168.0.1 but get ", ip)
+		}
+
+		var id testId
+		if err := reflection.SetValueInterface(&id, []byte("user-42")); err != nil {
+			t.Fatal(err)
+		}
+		if id.prefix != "user" || id.seq != 42 {
+			t.Fatal("expect user-42 but get ", id)
+		}
+		err := reflection.SetValueInterface(&id, "bad")
+		if err == nil || id.seq != 42 {
+			t.Fatal("expect error and id unchanged but get ", id, err)
+		}
+
+		b := big.Int{}
+		if err := reflection.SetValueInterface(&b, int64(42)); err != nil {
+			t.Fatal(err)
+		}
+		if b.Int64() != 42 {
+			t.Fatal("expect 42 but get ", b.String())
+		}
+
+		// 数值源值赋值给数值枚举类型时使用内置规则，字符串源值使用UnmarshalText
+		var l testLevel
+		if err := reflection.SetValueInterface(&l, int64(2)); err != nil || l != 2 {
+			t.Fatal("expect 2 but get ", l, err)
+		}
+		if err := reflection.SetValueInterface(&l, "debug"); err != nil || l != 1 {
+			t.Fatal("expect 1 but get ", l, err)
+		}
+	})
+
+	t.Run("scanner", func(t *testing.T) {
+		var ns sql.NullString
+		if err := reflection.SetValueInterface(&ns, "hello"); err != nil {
+			t.Fatal(err)
+		}
+		if !ns.Valid || ns.String != "hello" {
+			t.Fatal("expect hello but get ", ns)
+		}
+
+		var ni sql.NullInt64
+		if err := reflection.SetValueInterface(&ni, int64(7)); err != nil {
+			t.Fatal(err)
+		}
+		if !ni.Valid || ni.Int64 != 7 {
+			t.Fatal("expect 7 but get ", ni)
+		}
+	})
+
+	t.Run("time keeps built-in format", func(t *testing.T) {
+		v := time.Time{}
+		if err := reflection.SetValueInterface(&v, "2022-07-01 19:00:00"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("to string", func(t *testing.T) {
+		s := ""
+		if err := reflection.SetValueInterface(&s, testPoint{X: 1, Y: 2}); err != nil {
+			t.Fatal(err)
+		}
+		if s != "(1,2)" {
+			t.Fatal("expect (1,2) but get ", s)
+		}
+
+		if err := reflection.SetValueInterface(&s, testShade(3)); err != nil || s != "black" {
+			t.Fatal("expect black but get ", s, err)
+		}
+		if err := reflection.SetValueInterface(&s, testName("x")); err != nil || s != "name:x" {
+			t.Fatal("expect name:x but get ", s, err)
+		}
+		// 可直接赋值时不使用Stringer
+		n := testName("")
+		if err := reflection.SetValueInterface(&n, testName("y")); err != nil || n != "y" {
+			t.Fatal("expect y but get ", n, err)
+		}
+
+		if err := reflection.SetValueInterface(&s, testId{prefix: "order", seq: 1}); err != nil {
+			t.Fatal(err)
+		}
+		if s != "order-1" {
+			t.Fatal("expect order-1 but get ", s)
+		}
+
+		if err := reflection.SetValueInterface(&s, sql.NullString{String: "valuer", Valid: true}); err != nil {
+			t.Fatal(err)
+		}
+		if s != "valuer" {
+			t.Fatal("expect valuer but get ", s)
+		}
+
+		if err := reflection.SetValueInterface(&s, struct{ A int }{A: 1}); err != nil {
+			t.Fatal(err)
+		}
+		if s != "{1}" {
+			t.Fatal("expect {1} but get ", s)
+		}
+	})
+}
+
+func TestSetValuePointer(t *testing.T) {
+	t.Run("allocate", func(t *testing.T) {
+		var p *int
+		if err := reflection.SetValueInterface(&p, "42"); err != nil {
+			t.Fatal(err)
+		}
+		if p == nil || *p != 42 {
+			t.Fatal("expect 42 but get ", p)
+		}
+
+		var pp **int
+		if err := reflection.SetValueInterface(&pp, int64(7)); err != nil {
+			t.Fatal(err)
+		}
+		if pp == nil || *pp == nil || **pp != 7 {
+			t.Fatal("expect 7")
+		}
+
+		old := p
+		if err := reflection.SetValueInterface(&p, "x"); err == nil {
+			t.Fatal("expect error")
+		}
+		if p != old || *p != 42 {
+			t.Fatal("expect unchanged but get ", *p)
+		}
+	})
+
+	t.Run("keep aliasing", func(t *testing.T) {
+		x := 1
+		p := &x
+		if err := reflection.SetValueInterface(&p, "42"); err != nil {
+			t.Fatal(err)
+		}
+		if p != &x || x != 42 {
+			t.Fatal("expect x to be 42 but get ", x)
+		}
+
+		var inner *int
+		pp := &inner
+		if err := reflection.SetValueInterface(&pp, 7); err != nil {
+			t.Fatal(err)
+		}
+		if pp != &inner || inner == nil || *inner != 7 {
+			t.Fatal("expect inner to be allocated")
+		}
+		ip := inner
+		if err := reflection.SetValueInterface(&pp, 8); err != nil {
+			t.Fatal(err)
+		}
+		if *pp != ip || *ip != 8 {
+			t.Fatal("expect inner pointer unchanged")
+		}
+	})
+
+	t.Run("dereference", func(t *testing.T) {
+		str := "hello"
+		s := ""
+		if err := reflection.SetValueInterface(&s, &str); err != nil {
+			t.Fatal(err)
+		}
+		if s != "hello" {
+			t.Fatal("expect hello but get ", s)
+		}
+
+		num := "12"
+		var p *int64
+		if err := reflection.SetValueInterface(&p, &num); err != nil {
+			t.Fatal(err)
+		}
+		if *p != 12 {
+			t.Fatal("expect 12 but get ", *p)
+		}
+
+		var i int
+		var v interface{} = &num
+		if err := reflection.SetValueE(reflect.ValueOf(&i).Elem(), reflect.ValueOf(&v).Elem()); err != nil {
+			t.Fatal(err)
+		}
+		if i != 12 {
+			t.Fatal("expect 12 but get ", i)
+		}
+	})
+
+	t.Run("nil source", func(t *testing.T) {
+		i := 1
+		var np *string
+		if err := reflection.SetValueInterface(&i, np); err != nil {
+			t.Fatal(err)
+		}
+		if i != 0 {
+			t.Fatal("expect 0 but get ", i)
+		}
+
+		p := &i
+		if err := reflection.SetValueInterface(&p, nil); err != nil {
+			t.Fatal(err)
+		}
+		if p != nil {
+			t.Fatal("expect nil but get ", p)
+		}
+
+		s := "x"
+		if err := reflection.SetValueInterface(&s, nil); err != nil {
+			t.Fatal(err)
+		}
+		if s != "" {
+			t.Fatal("expect empty but get ", s)
+		}
+	})
+
+	t.Run("field", func(t *testing.T) {
+		type optional struct {
+			Age  *int
+			Name *string
+			Time *time.Time
+		}
+		o := optional{}
+		if err := reflection.SetStrcutFieldValue(&o, "Age", "18"); err != nil {
+			t.Fatal(err)
+		}
+		if err := reflection.SetStrcutFieldValue(&o, "Name", []byte("tom")); err != nil {
+			t.Fatal(err)
+		}
+		if err := reflection.SetStrcutFieldValue(&o, "Time", "2022-07-01"); err != nil {
+			t.Fatal(err)
+		}
+		if *o.Age != 18 || *o.Name != "tom" || o.Time.Format("2006-01-02") != "2022-07-01" {
+			t.Fatal("unexpected ", o)
+		}
+	})
+}
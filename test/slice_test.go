@@ -6,8 +6,10 @@
 package test
 
 import (
+	"errors"
 	"fmt"
 	"github.com/xfali/reflection"
+	"reflect"
 	"testing"
 )
 
@@ -89,4 +91,81 @@ func TestCopySlice(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("slice string int", func(t *testing.T) {
+		src := []string{"1", "x", "3", "y"}
+		var dst []int
+		n, err := reflection.CopySliceInterface(&dst, src)
+		if n != 2 || len(dst) != 2 || dst[0] != 1 || dst[1] != 3 {
+			t.Fatal("expect [1 3] get ", n, dst)
+		}
+		var copyErr *reflection.CopyError
+		if !errors.As(err, &copyErr) || len(copyErr.Elements) != 2 || copyErr.Elements[0].Index != 1 || copyErr.Elements[1].Index != 3 {
+			t.Fatal("expect failed index 1, 3 but get ", err)
+		}
+		t.Log(err)
+
+		old := []int{9}
+		if reflection.SetValue(reflect.ValueOf(&old).Elem(), reflect.ValueOf(src)) {
+			t.Fatal("must not set")
+		}
+		if len(old) != 1 || old[0] != 9 {
+			t.Fatal("dest must not be changed ", old)
+		}
+		if !reflection.SetValue(reflect.ValueOf(&old).Elem(), reflect.ValueOf([]string{"1", "2"})) {
+			t.Fatal("must set")
+		}
+		if len(old) != 2 || old[1] != 2 {
+			t.Fatal("expect [1 2] get ", old)
+		}
+	})
+
+	t.Run("slice int string", func(t *testing.T) {
+		var dst []string
+		n, err := reflection.CopySliceInterface(&dst, []int{65, 66})
+		if err != nil || n != 2 || dst[0] != "65" || dst[1] != "66" {
+			t.Fatal("expect [65 66] get ", n, dst, err)
+		}
+		if !reflection.SetValue(reflect.ValueOf(&dst).Elem(), reflect.ValueOf([]uint8{1})) || dst[0] != "1" {
+			t.Fatal("expect [1] get ", dst)
+		}
+	})
+
+	t.Run("slice checked registry", func(t *testing.T) {
+		reg := reflection.NewCheckedConverterRegistry(reflection.RoundReject)
+		var dst []int8
+		n, err := reflection.CopySliceInterface(&dst, []int64{1, 300}, reg)
+		var copyErr *reflection.CopyError
+		if n != 1 || !errors.As(err, &copyErr) || copyErr.Elements[0].Index != 1 || !errors.Is(err, reflection.ErrOverflow) {
+			t.Fatal("expect overflow at index 1 but get ", n, dst, err)
+		}
+		if len(dst) != 1 || dst[0] != 1 {
+			t.Fatal("expect [1] get ", dst)
+		}
+	})
+
+	t.Run("slice map struct", func(t *testing.T) {
+		src := []map[string]interface{}{
+			{"city": "shanghai", "street": "a"},
+			{"city": "hangzhou"},
+		}
+		var dst []testAddress
+		n, err := reflection.CopySliceInterface(&dst, src)
+		if err != nil || n != 2 {
+			t.Fatal("Must copy!", n, err)
+		}
+		if dst[0].City != "shanghai" || dst[0].Street != "a" || dst[1].City != "hangzhou" {
+			t.Fatal("unexpected ", dst)
+		}
+
+		var ptrs []*testAddress
+		n, err = reflection.CopySliceInterface(&ptrs, []interface{}{src[0], map[string]interface{}{"city": 1}, 2})
+		if n != 2 || ptrs[0].City != "shanghai" || ptrs[1].City != "1" {
+			t.Fatal("unexpected ", n, ptrs)
+		}
+		var copyErr *reflection.CopyError
+		if !errors.As(err, &copyErr) || len(copyErr.Elements) != 1 || copyErr.Elements[0].Index != 2 {
+			t.Fatal("expect failed index 2 but get ", err)
+		}
+	})
 }
@@ -0,0 +1,157 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"github.com/xfali/reflection"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testEncodeNode struct {
+	Name  string          `alias:"name"`
+	Next  *testEncodeNode `alias:"next"`
+	Count int             `alias:"count,omitempty"`
+	Id    int64           `alias:"id,string"`
+}
+
+func TestEncode(t *testing.T) {
+	age := 18
+	now := time.Now()
+	v := testCustomer{
+		testBase: testBase{
+			Id:        1,
+			CreatedAt: now,
+		},
+		Name: "tom",
+		Age:  &age,
+		Address: testAddress{
+			City: "shanghai",
+		},
+		Tags:      []string{"a", "b"},
+		History:   []testAddress{{City: "hangzhou"}},
+		Labels:    map[string]int{"x": 1},
+		Ignore:    "ignore",
+		Audit:     testAudit{Operator: "admin"},
+		Remain:    map[string]interface{}{"extra": true},
+		unexpored: "x",
+	}
+
+	t.Run("encode", func(t *testing.T) {
+		m, err := reflection.Encode(&v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Log(m)
+		if m["id"] != int64(1) || m["created_at"] != now {
+			t.Fatal("embedded not inlined ", m)
+		}
+		if m["name"] != "tom" || m["age"] != 18 || m["backup"] != nil {
+			t.Fatal("unexpected ", m)
+		}
+		if addr, ok := m["address"].(map[string]interface{}); !ok || addr["city"] != "shanghai" {
+			t.Fatal("unexpected address ", m["address"])
+		}
+		if tags, ok := m["tags"].([]interface{}); !ok || len(tags) != 2 || tags[1] != "b" {
+			t.Fatal("unexpected tags ", m["tags"])
+		}
+		if h, ok := m["history"].([]interface{}); !ok || h[0].(map[string]interface{})["city"] != "hangzhou" {
+			t.Fatal("unexpected history ", m["history"])
+		}
+		if l, ok := m["labels"].(map[string]interface{}); !ok || l["x"] != 1 {
+			t.Fatal("unexpected labels ", m["labels"])
+		}
+		if m["operator"] != "admin" || m["extra"] != true {
+			t.Fatal("squash or remain not inlined ", m)
+		}
+		if _, ok := m["Ignore"]; ok {
+			t.Fatal("must be ignored")
+		}
+		if _, ok := m["unexpored"]; ok {
+			t.Fatal("must be ignored")
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		m, err := reflection.Encode(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o := testCustomer{}
+		if err := reflection.Decode(m, &o); err != nil {
+			t.Fatal(err)
+		}
+		o.Ignore, o.unexpored = v.Ignore, v.unexpored
+		if !reflect.DeepEqual(v, o) {
+			t.Fatalf("expect %v but get %v", v, o)
+		}
+	})
+
+	t.Run("nil pointer", func(t *testing.T) {
+		m, err := reflection.Encode(v, reflection.EncodeNilPointer(reflection.NilPointerOmit))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := m["backup"]; ok {
+			t.Fatal("must be omitted")
+		}
+
+		m, err = reflection.Encode(v, reflection.EncodeNilPointer(reflection.NilPointerZero))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b, ok := m["backup"].(map[string]interface{}); !ok || b["city"] != "" {
+			t.Fatal("expect zero address but get ", m["backup"])
+		}
+	})
+
+	t.Run("options", func(t *testing.T) {
+		n := testEncodeNode{Name: "a", Id: 10, Next: &testEncodeNode{Name: "b", Count: 1}}
+		m, err := reflection.Encode(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := m["count"]; ok {
+			t.Fatal("must be omitted")
+		}
+		if m["id"] != "10" {
+			t.Fatal("expect string 10 but get ", m["id"])
+		}
+		if next := m["next"].(map[string]interface{}); next["count"] != 1 {
+			t.Fatal("expect 1 but get ", next["count"])
+		}
+
+		n.Next.Next = &n
+		if _, err := reflection.Encode(&n); err == nil {
+			t.Fatal("expect cycle error")
+		}
+	})
+	t.Run("pointer receiver marshaler", func(t *testing.T) {
+		type Order struct {
+			Amount *big.Int `alias:"amount"`
+			Total  big.Int  `alias:"total"`
+		}
+		o := Order{Amount: big.NewInt(5)}
+		o.Total.SetInt64(7)
+		m, err := reflection.Encode(&o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a, ok := m["amount"].(*big.Int); !ok || a.Int64() != 5 {
+			t.Fatal("expect *big.Int 5 but get ", m["amount"])
+		}
+		if _, ok := m["total"].(big.Int); !ok {
+			t.Fatal("expect big.Int leaf but get ", m["total"])
+		}
+
+		d := Order{}
+		if err := reflection.Decode(m, &d); err != nil || d.Amount.Int64() != 5 || d.Total.Int64() != 7 {
+			t.Fatal("round trip failed ", d, err)
+		}
+	})
+}
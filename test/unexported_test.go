@@ -0,0 +1,116 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"errors"
+	"github.com/xfali/reflection"
+	"reflect"
+	"testing"
+)
+
+type testHidden struct {
+	token string
+}
+
+type testPrivate struct {
+	*testHidden
+	Name   string
+	secret string
+	count  int
+	inner  testHidden
+}
+
+func TestUnexportedDefault(t *testing.T) {
+	o := testPrivate{Name: "a", secret: "s", count: 1}
+
+	if err := reflection.Set(&o, "secret", "x"); !errors.Is(err, reflection.ErrFieldNotFound) {
+		t.Fatal("expect ErrFieldNotFound but get ", err)
+	}
+	if err := reflection.Set(&o, "token", "x", reflection.WithAutoCreate()); !errors.Is(err, reflection.ErrFieldNotFound) {
+		t.Fatal("expect ErrFieldNotFound but get ", err)
+	}
+	if err := reflection.SetStrcutFieldValue(&o, "count", 2); !errors.Is(err, reflection.ErrFieldNotFound) {
+		t.Fatal("expect ErrFieldNotFound but get ", err)
+	}
+	if _, err := reflection.Get(o, "secret"); !errors.Is(err, reflection.ErrFieldNotFound) {
+		t.Fatal("expect ErrFieldNotFound but get ", err)
+	}
+	if _, err := reflection.CompilePath(reflect.TypeOf(o), "inner.token"); !errors.Is(err, reflection.ErrFieldNotFound) {
+		t.Fatal("expect ErrFieldNotFound but get ", err)
+	}
+	if o.secret != "s" || o.count != 1 || o.testHidden != nil {
+		t.Fatal("unexported state must not be touched ", o)
+	}
+
+	info, err := reflection.GetStructInfo(&o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := info.MapValue()
+	if len(m) != 1 || m["Name"] != "a" {
+		t.Fatal("expect only Name but get ", m)
+	}
+	if info.SetField("secret", reflect.ValueOf("x")) || o.secret != "s" {
+		t.Fatal("unexported state must not be touched ", o)
+	}
+
+	em, err := reflection.Encode(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(em) != 1 || em["Name"] != "a" {
+		t.Fatal("expect only Name but get ", em)
+	}
+}
+
+func TestUnexportedAllowed(t *testing.T) {
+	opt := reflection.AllowUnexported()
+	o := testPrivate{Name: "a", secret: "s", count: 1}
+
+	if err := reflection.Set(&o, "secret", "x", opt); err != nil {
+		t.Fatal(err)
+	}
+	if err := reflection.Set(&o, "count", "2", opt); err != nil {
+		t.Fatal(err)
+	}
+	if err := reflection.Set(&o, "inner.token", "i", opt); err != nil {
+		t.Fatal(err)
+	}
+	if err := reflection.Set(&o, "token", "e", opt, reflection.WithAutoCreate()); err != nil {
+		t.Fatal(err)
+	}
+	if o.secret != "x" || o.count != 2 || o.inner.token != "i" || o.testHidden == nil || o.testHidden.token != "e" {
+		t.Fatal("unexpected ", o)
+	}
+
+	v, err := reflection.Get(o, "inner.token", opt)
+	if err != nil || v != "i" {
+		t.Fatal("expect i but get ", v, err)
+	}
+
+	a, err := reflection.CompilePath(reflect.TypeOf(o), "count", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Set(&o, 3); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := a.Get(&o); err != nil || v != 3 {
+		t.Fatal("expect 3 but get ", v, err)
+	}
+
+	m, err := reflection.Encode(o, reflection.EncodeUnexported())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["secret"] != "x" || m["count"] != 3 || m["token"] != "e" {
+		t.Fatal("unexpected ", m)
+	}
+	if inner, ok := m["inner"].(map[string]interface{}); !ok || inner["token"] != "i" {
+		t.Fatal("unexpected inner ", m["inner"])
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package test
+
+import (
+	"errors"
+	"github.com/xfali/reflection"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testWalkNode struct {
+	testBase
+	Name     string
+	Password string `redact:"true"`
+	Ignore   string `alias:"-"`
+	Items    []testPathItem
+	Labels   map[string]int
+	Next     *testWalkNode
+	At       time.Time
+	hidden   string
+}
+
+func walkPaths(obj interface{}, opts ...reflection.WalkOption) ([]string, error) {
+	var paths []string
+	err := reflection.Walk(obj, func(path string, field reflect.StructField, v reflect.Value) error {
+		paths = append(paths, path)
+		return nil
+	}, opts...)
+	return paths, err
+}
+
+func TestWalk(t *testing.T) {
+	o := &testWalkNode{
+		testBase: testBase{Id: 1},
+		Name:     "root",
+		Items:    []testPathItem{{Name: "a"}},
+		Labels:   map[string]int{"b": 2, "a": 1},
+		Next:     &testWalkNode{Name: "next"},
+		hidden:   "h",
+	}
+
+	t.Run("paths", func(t *testing.T) {
+		paths, err := walkPaths(o, reflection.WalkMaxDepth(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := []string{
+			"Id", "CreatedAt", "Name", "Password",
+			"Items", "Items[0]",
+			"Labels", `Labels["a"]`, `Labels["b"]`,
+			"Next", "Next.Id", "Next.CreatedAt", "Next.Name", "Next.Password", "Next.Items", "Next.Labels", "Next.Next", "Next.At",
+			"At",
+		}
+		if !reflect.DeepEqual(paths, expect) {
+			t.Fatalf("expect %v but get %v", expect, paths)
+		}
+		for _, p := range paths {
+			if _, err := reflection.Get(o, p); err != nil {
+				t.Fatal(p, err)
+			}
+		}
+	})
+
+	t.Run("redact", func(t *testing.T) {
+		err := reflection.Walk(o, func(path string, field reflect.StructField, v reflect.Value) error {
+			if field.Tag.Get("redact") == "true" {
+				return reflection.Set(o, path, "***")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if o.Password != "***" || o.Next.Password != "***" {
+			t.Fatal("expect redacted but get ", o.Password, o.Next.Password)
+		}
+	})
+
+	t.Run("skip and stop", func(t *testing.T) {
+		var paths []string
+		err := reflection.Walk(o, func(path string, field reflect.StructField, v reflect.Value) error {
+			paths = append(paths, path)
+			if path == "Next" || path == "Items" {
+				return reflection.SkipChildren
+			}
+			if path == "Labels" {
+				return reflection.Stop
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(paths, ",") != "Id,CreatedAt,Name,Password,Items,Labels" {
+			t.Fatal("unexpected ", paths)
+		}
+
+		expect := errors.New("test")
+		err = reflection.Walk(o, func(path string, field reflect.StructField, v reflect.Value) error {
+			return expect
+		})
+		if err != expect {
+			t.Fatal("expect test error but get ", err)
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		c := &testWalkNode{Name: "c"}
+		c.Next = c
+		paths, err := walkPaths(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if paths[len(paths)-2] != "Next" {
+			t.Fatal("unexpected ", paths)
+		}
+
+		m := map[string]interface{}{}
+		m["self"] = m
+		paths, err = walkPaths(m)
+		if err != nil || len(paths) != 1 || paths[0] != `["self"]` {
+			t.Fatal("unexpected ", paths, err)
+		}
+	})
+
+	t.Run("tag", func(t *testing.T) {
+		paths, err := walkPaths(testWalkNode{}, reflection.WalkTagName("redact"), reflection.WalkMaxDepth(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, p := range paths {
+			if p == "Ignore" {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatal("expect Ignore but get ", paths)
+		}
+	})
+}
@@ -126,7 +126,7 @@ var SqlType2GoType = map[string]reflect.Type{
 	"varbinary":          StringType,
 }
 
-// IsSimpleType 是否是数据库使用的简单类型，注意不能是PTR
+// IsSimpleType 是否是数据库使用的简单类型，注意不能是PTR。通过RegisterSimpleType声明的类型也是简单类型
 func IsSimpleType(t reflect.Type) bool {
 	switch t.Kind() {
 	case IntKind, Int8Kind, Int16Kind, Int32Kind, Int64Kind, UintKind, Uint8Kind, Uint16Kind, Uint32Kind, Uint64Kind,
@@ -137,5 +137,5 @@ func IsSimpleType(t reflect.Type) bool {
 	if t.ConvertibleTo(BytesType) || t.ConvertibleTo(TimeType) {
 		return true
 	}
-	return false
+	return DefaultObjectFactoryRegistry.IsSimpleType(t)
 }
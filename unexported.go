@@ -0,0 +1,49 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package reflection
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// 未导出字段的读写只在显式开启（AllowUnexported、EncodeUnexported）时使用，默认模式不会调用本文件中的函数
+
+// exposeField 通过unsafe获得可读写的未导出字段，v不可寻址或本身可以访问时返回原值
+func exposeField(v reflect.Value) reflect.Value {
+	if v.CanInterface() || !v.CanAddr() {
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// addressable 返回可寻址的值，不可寻址时复制一份
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	cp := reflect.New(v.Type()).Elem()
+	cp.Set(v)
+	return cp
+}
+
+// fieldByIndexUnexported 按索引路径获得字段，路径上的未导出字段（包括未导出的嵌入结构体指针）均可读写。
+// create为true时分配路径上的nil嵌入指针，否则返回无效值
+func fieldByIndexUnexported(v reflect.Value, index []int, create bool) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !create || !v.CanSet() {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = exposeField(v.Field(x))
+	}
+	return v
+}
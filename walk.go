@@ -0,0 +1,198 @@
+// Copyright (C) 2019-2022, Xiongfa Li.
+// @author xiongfa.li
+// @version V1.0
+// Description:
+
+package reflection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+var (
+	// SkipChildren WalkFunc返回该错误时不再遍历当前值的子节点
+	SkipChildren = errors.New("skip children")
+	// Stop WalkFunc返回该错误时停止遍历，Walk返回nil
+	Stop = errors.New("stop walk")
+)
+
+// WalkFunc 遍历回调。
+// path为值的路径（语法见SetFieldValueEx，字段使用字段名称，可直接用于Set、Get）；
+// field为值所属的结构体字段，slice、array、map元素为其所在的字段，顶层容器的元素为零值；
+// v为字段或元素的值（指针、interface不解引用），map元素不可寻址
+type WalkFunc func(path string, field reflect.StructField, v reflect.Value) error
+
+// WalkOption 配置Walk
+type WalkOption func(w *walker)
+
+type walker struct {
+	tagName  string
+	maxDepth int
+	fn       WalkFunc
+	// 当前路径上的指针、map，用于检测循环引用
+	visiting map[walkRef]bool
+}
+
+type walkRef struct {
+	p uintptr
+	t reflect.Type
+}
+
+// WalkTagName 指定过滤字段使用的tag，tag名称为‘-’的字段不遍历，默认为StructAliasTag
+func WalkTagName(tagName string) WalkOption {
+	return func(w *walker) {
+		w.tagName = tagName
+	}
+}
+
+// WalkMaxDepth 最大遍历深度，顶层字段（元素）深度为1，默认为0表示不限制
+func WalkMaxDepth(depth int) WalkOption {
+	return func(w *walker) {
+		w.maxDepth = depth
+	}
+}
+
+// Walk 深度优先遍历obj中的结构体字段以及slice、array、map元素，对每个节点调用fn：
+// 1、先调用fn再遍历子节点，fn返回SkipChildren时跳过子节点，返回Stop时停止遍历，返回其他错误时停止遍历并返回该错误；
+// 2、指针、interface透明解引用，nil值不遍历子节点，循环引用的值不再遍历子节点；
+// 3、匿名结构体字段按提升规则展开，未导出字段不遍历；
+// 4、time.Time以及实现了encoding.TextMarshaler的结构体作为叶子节点；
+// 5、map按key的字符串形式排序遍历。
+func Walk(obj interface{}, fn WalkFunc, opts ...WalkOption) error {
+	if fn == nil {
+		return errors.New("Walk func must not be nil. ")
+	}
+	w := &walker{
+		tagName:  StructAliasTag,
+		fn:       fn,
+		visiting: map[walkRef]bool{},
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	err := w.walkChildren("", reflect.StructField{}, reflect.ValueOf(obj), 0)
+	if err == Stop {
+		return nil
+	}
+	return err
+}
+
+func (w *walker) visit(path string, field reflect.StructField, v reflect.Value, depth int) error {
+	if w.maxDepth > 0 && depth > w.maxDepth {
+		return nil
+	}
+	if err := w.fn(path, field, v); err != nil {
+		if err == SkipChildren {
+			return nil
+		}
+		return err
+	}
+	return w.walkChildren(path, field, v, depth)
+}
+
+func (w *walker) walkChildren(path string, field reflect.StructField, v reflect.Value, depth int) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Ptr {
+			ref := walkRef{p: v.Pointer(), t: v.Type()}
+			if w.visiting[ref] {
+				return nil
+			}
+			w.visiting[ref] = true
+			defer delete(w.visiting, ref)
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if isEncodeLeaf(v.Type()) {
+			return nil
+		}
+		desc := getStructDescriptor(v.Type(), []string{w.tagName}, nil, false)
+		for _, f := range desc.Fields {
+			fv := fieldByIndexForGet(v, f.Index)
+			if !fv.IsValid() {
+				continue
+			}
+			if err := w.visit(joinPath(path, f.Name), v.Type().FieldByIndex(f.Index), fv, depth+1); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				return nil
+			}
+			ref := walkRef{p: v.Pointer(), t: v.Type()}
+			if w.visiting[ref] {
+				return nil
+			}
+			w.visiting[ref] = true
+			defer delete(w.visiting, ref)
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := w.visit(path+"["+strconv.Itoa(i)+"]", field, v.Index(i), depth+1); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		ref := walkRef{p: v.Pointer(), t: v.Type()}
+		if w.visiting[ref] {
+			return nil
+		}
+		w.visiting[ref] = true
+		defer delete(w.visiting, ref)
+
+		keys := v.MapKeys()
+		names := make([]string, len(keys))
+		for i, k := range keys {
+			names[i] = mapKeySegment(k)
+		}
+		sort.Sort(&keySorter{keys: keys, names: names})
+		for i, k := range keys {
+			if err := w.visit(path+names[i], field, v.MapIndex(k), depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mapKeySegment 将map key格式化为路径片段，字符串key带引号
+func mapKeySegment(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return "[" + strconv.Quote(k.String()) + "]"
+	}
+	if k.CanInterface() {
+		return fmt.Sprintf("[%v]", k.Interface())
+	}
+	return fmt.Sprintf("[%v]", k)
+}
+
+type keySorter struct {
+	keys  []reflect.Value
+	names []string
+}
+
+func (s *keySorter) Len() int {
+	return len(s.keys)
+}
+
+func (s *keySorter) Less(i, j int) bool {
+	return s.names[i] < s.names[j]
+}
+
+func (s *keySorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.names[i], s.names[j] = s.names[j], s.names[i]
+}